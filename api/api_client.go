@@ -324,6 +324,21 @@ func reportError(format string, a ...interface{}) (error) {
 	return fmt.Errorf(format, a...)
 }
 
+// ResponseError is returned when the server responds with a non-2xx status.
+// Body holds the drained response body.
+type ResponseError struct {
+	Response *http.Response
+	Body     []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("Status: %v, Body: %s", e.Response.Status, e.Body)
+}
+
+func newResponseError(r *http.Response, body []byte) (error) {
+	return &ResponseError{Response: r, Body: body}
+}
+
 // Set request body from an interface{}
 func setBody(body interface{}, contentType string) (bodyBuf *bytes.Buffer, err error) {
 	if bodyBuf == nil {
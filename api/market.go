@@ -29,4 +29,10 @@ type Market struct {
 
 	// fixed precision of quote unit
 	QuoteUnitPrecision int32 `json:"quote_unit_precision,omitempty"`
+
+	// minimum order volume in base unit
+	MinBaseAmount float64 `json:"min_base_amount,omitempty"`
+
+	// minimum order amount in quote unit
+	MinQuoteAmount float64 `json:"min_quote_amount,omitempty"`
 }
@@ -84,7 +84,7 @@ func (a *PrivateApiService) GetApiV2Deposit(ctx context.Context, xMAXACCESSKEY s
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -160,7 +160,7 @@ func (a *PrivateApiService) GetApiV2DepositAddress(ctx context.Context, xMAXACCE
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -236,7 +236,7 @@ func (a *PrivateApiService) GetApiV2DepositAddresses(ctx context.Context, xMAXAC
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -361,7 +361,7 @@ func (a *PrivateApiService) GetApiV2Deposits(ctx context.Context, xMAXACCESSKEY
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -428,7 +428,7 @@ func (a *PrivateApiService) GetApiV2MembersMe(ctx context.Context, xMAXACCESSKEY
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -497,7 +497,7 @@ func (a *PrivateApiService) GetApiV2Order(ctx context.Context, xMAXACCESSKEY str
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -610,7 +610,7 @@ func (a *PrivateApiService) GetApiV2Orders(ctx context.Context, xMAXACCESSKEY st
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -737,7 +737,7 @@ func (a *PrivateApiService) GetApiV2TradesMy(ctx context.Context, xMAXACCESSKEY
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -806,7 +806,7 @@ func (a *PrivateApiService) GetApiV2Withdrawal(ctx context.Context, xMAXACCESSKE
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -931,7 +931,7 @@ func (a *PrivateApiService) GetApiV2Withdrawals(ctx context.Context, xMAXACCESSK
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -1000,7 +1000,7 @@ func (a *PrivateApiService) PostApiV2DepositAddresses(ctx context.Context, xMAXA
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -1069,7 +1069,7 @@ func (a *PrivateApiService) PostApiV2OrderDelete(ctx context.Context, xMAXACCESS
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -1165,7 +1165,7 @@ func (a *PrivateApiService) PostApiV2Orders(ctx context.Context, xMAXACCESSKEY s
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -1248,7 +1248,7 @@ func (a *PrivateApiService) PostApiV2OrdersClear(ctx context.Context, xMAXACCESS
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -1334,7 +1334,7 @@ func (a *PrivateApiService) PostApiV2OrdersMulti(ctx context.Context, xMAXACCESS
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 	if err = json.NewDecoder(localVarHTTPResponse.Body).Decode(&successPayload); err != nil {
@@ -79,7 +79,7 @@ func (a *PublicApiService) GetApiV2Currencies(ctx context.Context) ([]Currency,
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
@@ -270,7 +270,7 @@ func (a *PublicApiService) GetApiV2Markets(ctx context.Context) ([]Market,  *htt
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
@@ -350,7 +350,7 @@ func (a *PublicApiService) GetApiV2OrderBook(ctx context.Context, market string,
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
@@ -413,7 +413,7 @@ func (a *PublicApiService) GetApiV2Tickers(ctx context.Context) (Tickers,  *http
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
@@ -478,7 +478,7 @@ func (a *PublicApiService) GetApiV2TickersMarket(ctx context.Context, market str
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
@@ -647,7 +647,7 @@ func (a *PublicApiService) GetApiV2Trades(ctx context.Context, market string, lo
 	defer localVarHTTPResponse.Body.Close()
 	if localVarHTTPResponse.StatusCode >= 300 {
 		bodyBytes, _ := ioutil.ReadAll(localVarHTTPResponse.Body)
-		return successPayload, localVarHTTPResponse, reportError("Status: %v, Body: %s", localVarHTTPResponse.Status, bodyBytes)
+		return successPayload, localVarHTTPResponse, newResponseError(localVarHTTPResponse, bodyBytes)
 	}
 
 
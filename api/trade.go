@@ -35,4 +35,10 @@ type Trade struct {
 
 	// order related to you
 	OrderId int32 `json:"order_id,omitempty"`
+
+	// trading fee paid, only for your trades
+	Fee string `json:"fee,omitempty"`
+
+	// currency of the trading fee, only for your trades
+	FeeCurrency string `json:"fee_currency,omitempty"`
 }
@@ -15,6 +15,7 @@
 package max
 
 import (
+	"context"
 	"time"
 
 	"github.com/maicoin/max-exchange-api-go/types"
@@ -31,6 +32,24 @@ func defaultOptions() Options {
 	return make(map[string]interface{})
 }
 
+const responseMetaOption = "_response_meta"
+
+// callOptions applies the CallOptions of a call, and moves the ones meant for
+// the HTTP layer, e.g. WithResponse(), into the context.
+func callOptions(ctx context.Context, opts []CallOption) (context.Context, Options) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if meta, ok := o[responseMetaOption].(*ResponseMeta); ok {
+		delete(o, responseMetaOption)
+		ctx = context.WithValue(ctx, responseMetaKey{}, meta)
+	}
+
+	return ctx, o
+}
+
 // ----------------------------------------------------------------------------
 
 // CallOption represents the API parameters
@@ -67,7 +86,7 @@ func Timestamp(timestamp int32) CallOption {
 // Time represents the timestamp parameter in Go time.Time format
 func Time(t time.Time) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["timestamp"] = t.Unix()
+		opt["timestamp"] = int32(t.Unix())
 	}
 }
 
@@ -81,7 +100,7 @@ func From(from int32) CallOption {
 // FromTime represents the from parameter in Go time.Time format
 func FromTime(from time.Time) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["from"] = from.Unix()
+		opt["from"] = int32(from.Unix())
 	}
 }
 
@@ -95,7 +114,7 @@ func To(to int32) CallOption {
 // ToTime represents the to parameter
 func ToTime(to time.Time) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["to"] = to.Unix()
+		opt["to"] = int32(to.Unix())
 	}
 }
 
@@ -123,7 +142,7 @@ func Period(period int32) CallOption {
 // PeriodDuration represents the period parameter in Go time.Duration format
 func PeriodDuration(period time.Duration) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["period"] = int64(period.Minutes())
+		opt["period"] = int32(period.Minutes())
 	}
 }
 
@@ -144,56 +163,67 @@ func Offset(offset int32) CallOption {
 // DepositState represents the state parameter for deposit
 func DepositState(state types.DepositState) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["state"] = state
+		opt["state"] = string(state)
 	}
 }
 
 // WithdrawalState represents the state parameter for withdrawal
 func WithdrawalState(state types.WithdrawalState) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["state"] = state
+		opt["state"] = string(state)
+	}
+}
+
+// OrderState represents the state parameter for order
+func OrderState(state types.OrderState) CallOption {
+	return func(opt map[string]interface{}) {
+		opt["state"] = string(state)
 	}
 }
 
 // Price represents the price parameter
 func Price(price types.Price) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["price"] = price
+		opt["price"] = price.String()
 	}
 }
 
 // Prices represents the orders[price] parameter
 func Prices(prices []types.Price) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["orders[price]"] = prices
+		opt["orders[price]"] = decimalStrings(prices)
 	}
 }
 
 // StopPrice represents the stop_price parameter
 func StopPrice(price types.Price) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["stop_price"] = price
+		opt["stop_price"] = price.String()
 	}
 }
 
 // StopPrices represents the orders[stop_price] parameter
 func StopPrices(prices []types.Price) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["orders[stop_price]"] = prices
+		opt["orders[stop_price]"] = decimalStrings(prices)
 	}
 }
 
 // OrderType represents the ord_type parameter
 func OrderType(t types.OrderType) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["ord_type"] = t
+		opt["ord_type"] = string(t)
 	}
 }
 
 // OrderTypes represents the orders[ord_type] parameter
 func OrderTypes(t []types.OrderType) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["orders[ord_type"] = t
+		names := make([]string, len(t))
+		for i, ot := range t {
+			names[i] = string(ot)
+		}
+		opt["orders[ord_type]"] = names
 	}
 }
 
@@ -214,7 +244,7 @@ func Page(page int32) CallOption {
 // OrderSide represents the side parameter
 func OrderSide(t types.OrderSide) CallOption {
 	return func(opt map[string]interface{}) {
-		opt["side"] = t
+		opt["side"] = string(t)
 	}
 }
 
@@ -224,3 +254,19 @@ func Market(market string) CallOption {
 		opt["market"] = market
 	}
 }
+
+func decimalStrings(ds []types.Decimal) []string {
+	results := make([]string, len(ds))
+	for i, d := range ds {
+		results[i] = d.String()
+	}
+	return results
+}
+
+// WithResponse captures the HTTP response metadata of the call into meta,
+// it is filled even if the call fails. Iterators capture the last fetched page.
+func WithResponse(meta *ResponseMeta) CallOption {
+	return func(opt map[string]interface{}) {
+		opt[responseMetaOption] = meta
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// CandleUpdate is passed to the OnCandle() handlers of a CandleAggregator.
+type CandleUpdate struct {
+	Candle models.Candle
+	// Closed reports the final value of the candle, otherwise the candle
+	// is partial and updated by the next trades.
+	Closed bool
+}
+
+// CandleAggregator builds the OHLCV candles of a market from its trades,
+// for intervals from a second to a week. Candles start at multiples of the
+// interval since the zero time, e.g. at 0, 5, 10 minutes for 5 minutes.
+//
+// Bars without trades are closed as flat candles at the previous close
+// price, once a trade of a later bar arrives or their time is over.
+//
+//	agg, err := max.NewCandleAggregator(5 * time.Minute)
+//	agg.OnCandle(func(u max.CandleUpdate) { ... })
+//	sub, err := ws.SubscribeTrade("btctwd", make(chan *models.TradeEvent, 100))
+//	err = agg.Seed(ctx, client, "btctwd")
+//	go agg.Run(ctx, sub.Chan())
+type CandleAggregator struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	current *models.Candle
+	// close price and end of the last closed candle, for the flat candles
+	lastClose *types.Price
+	next      time.Time
+	// trades before are part of the seeded candle
+	seededAt time.Time
+
+	handlersMu sync.RWMutex
+	handlers   []func(CandleUpdate)
+}
+
+// NewCandleAggregator returns an aggregator of candles of the interval,
+// from a second to a week.
+func NewCandleAggregator(interval time.Duration) (*CandleAggregator, error) {
+	if interval < time.Second || interval > 7*24*time.Hour || interval%time.Second != 0 {
+		return nil, fmt.Errorf("max: unsupported candle interval %v", interval)
+	}
+
+	return &CandleAggregator{interval: interval}, nil
+}
+
+// OnCandle registers a handler called with the partial candle after every
+// trade, and with the closed candles.
+func (a *CandleAggregator) OnCandle(h func(CandleUpdate)) {
+	a.handlersMu.Lock()
+	defer a.handlersMu.Unlock()
+
+	a.handlers = append(a.handlers, h)
+}
+
+func (a *CandleAggregator) notify(updates []CandleUpdate) {
+	a.handlersMu.RLock()
+	defer a.handlersMu.RUnlock()
+
+	for _, u := range updates {
+		for _, h := range a.handlers {
+			h(u)
+		}
+	}
+}
+
+// Current returns the candle in progress.
+func (a *CandleAggregator) Current() (models.Candle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.current == nil {
+		return models.Candle{}, false
+	}
+	return *a.current, true
+}
+
+// Seed loads the current bar from K(), with the largest period of
+// CandlePeriods dividing the interval. The trades executed before the
+// K() response are then ignored as they are part of its candles.
+func (a *CandleAggregator) Seed(ctx context.Context, api PublicAPI, market string) error {
+	var period time.Duration
+	for _, p := range CandlePeriods {
+		if p <= a.interval && a.interval%p == 0 {
+			period = p
+		}
+	}
+	if period == 0 {
+		return fmt.Errorf("max: cannot seed %v candles from K()", a.interval)
+	}
+
+	start := time.Now().Truncate(a.interval)
+	candles, err := api.K(ctx, market, PeriodDuration(period), Time(start), Limit(int32(a.interval/period)+1))
+	if err != nil {
+		return err
+	}
+	seededAt := time.Now()
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Time.Before(candles[j].Time)
+	})
+
+	a.mu.Lock()
+	var updates []CandleUpdate
+	for _, c := range candles {
+		if c.Time.Before(start) {
+			continue
+		}
+		updates = append(updates, a.apply(c.Time, c.Open, c.High, c.Low, c.Close, c.Volume)...)
+	}
+	a.seededAt = seededAt
+	a.mu.Unlock()
+
+	a.notify(updates)
+
+	return nil
+}
+
+// Add adds a trade of the websocket stream.
+func (a *CandleAggregator) Add(trade *models.TradeEvent) {
+	a.mu.Lock()
+	var updates []CandleUpdate
+	if !trade.At.Before(a.seededAt) {
+		updates = a.apply(trade.At, trade.Price, trade.Price, trade.Price, trade.Price, trade.Volume)
+	}
+	a.mu.Unlock()
+
+	a.notify(updates)
+}
+
+// AddTrades adds trades of the Trades() history, in any order.
+func (a *CandleAggregator) AddTrades(trades []*models.Trade) {
+	sorted := append([]*models.Trade{}, trades...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	for _, t := range sorted {
+		a.Add(&models.TradeEvent{At: t.CreatedAt, Market: t.Market, Price: t.Price, Volume: t.Volume})
+	}
+}
+
+// Tick closes the current candle, and the flat candles after it, if
+// their time is over at now.
+func (a *CandleAggregator) Tick(now time.Time) {
+	a.mu.Lock()
+	updates := a.closeUntil(now.Truncate(a.interval))
+	a.mu.Unlock()
+
+	a.notify(updates)
+}
+
+// Run adds the trades of the channel, e.g. of SubscribeTrade(), and closes
+// the candles on time, until the channel is closed or ctx is done.
+func (a *CandleAggregator) Run(ctx context.Context, trades <-chan *models.TradeEvent) error {
+	ticker := time.NewTicker(a.tickInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case trade, ok := <-trades:
+			if !ok {
+				return nil
+			}
+			a.Add(trade)
+		case now := <-ticker.C:
+			a.Tick(now)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (a *CandleAggregator) tickInterval() time.Duration {
+	if a.interval < time.Minute {
+		return a.interval / 4
+	}
+	return time.Second
+}
+
+// apply merges a trade or a candle at t into the candles, must be called
+// with a.mu held.
+func (a *CandleAggregator) apply(t time.Time, open, high, low, close types.Price, volume types.Volume) []CandleUpdate {
+	start := t.Truncate(a.interval)
+	if (a.current != nil && start.Before(a.current.Time)) || start.Before(a.next) {
+		// Late data of a closed candle.
+		return nil
+	}
+
+	updates := a.closeUntil(start)
+
+	if a.current == nil {
+		a.current = &models.Candle{Time: start, Open: open, High: high, Low: low, Close: close, Volume: volume}
+	} else {
+		c := a.current
+		if high.GreaterThan(c.High) {
+			c.High = high
+		}
+		if low.LessThan(c.Low) {
+			c.Low = low
+		}
+		c.Close = close
+		c.Volume = c.Volume.Add(volume)
+	}
+
+	return append(updates, CandleUpdate{Candle: *a.current})
+}
+
+// closeUntil closes the current candle and the flat candles of the bars
+// before start, must be called with a.mu held.
+func (a *CandleAggregator) closeUntil(start time.Time) []CandleUpdate {
+	var updates []CandleUpdate
+
+	if a.current != nil && a.current.Time.Before(start) {
+		updates = append(updates, CandleUpdate{Candle: *a.current, Closed: true})
+		price := a.current.Close
+		a.lastClose = &price
+		a.next = a.current.Time.Add(a.interval)
+		a.current = nil
+	}
+
+	if a.current == nil && a.lastClose != nil {
+		price := *a.lastClose
+		for ; a.next.Before(start); a.next = a.next.Add(a.interval) {
+			updates = append(updates, CandleUpdate{
+				Candle: models.Candle{Time: a.next, Open: price, High: price, Low: price, Close: price},
+				Closed: true,
+			})
+		}
+	}
+
+	return updates
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestCandleAggregator(t *testing.T) {
+	if _, err := NewCandleAggregator(time.Millisecond); err == nil {
+		t.Error("no error for a millisecond interval")
+	}
+
+	agg, err := NewCandleAggregator(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var closed []models.Candle
+	partials := 0
+	agg.OnCandle(func(u CandleUpdate) {
+		if u.Closed {
+			closed = append(closed, u.Candle)
+		} else {
+			partials++
+		}
+	})
+
+	trade := func(sec int64, price, volume string) *models.TradeEvent {
+		return &models.TradeEvent{
+			At:     time.Unix(sec, 0),
+			Market: "btctwd",
+			Price:  types.MustParseDecimal(price),
+			Volume: types.MustParseDecimal(volume),
+		}
+	}
+
+	agg.Add(trade(60, "10", "1"))
+	agg.Add(trade(70, "12", "2"))
+	agg.Add(trade(80, "9", "1"))
+	agg.Add(trade(90, "11", "0.5"))
+	// The minute at 120 has no trade.
+	agg.Add(trade(190, "13", "1"))
+	// Late trade of a closed candle.
+	agg.Add(trade(100, "100", "1"))
+
+	if partials != 5 {
+		t.Errorf("%d partial candles, want 5", partials)
+	}
+	if len(closed) != 2 {
+		t.Fatalf("%d closed candles, want 2", len(closed))
+	}
+
+	c := closed[0]
+	if c.Time.Unix() != 60 || c.Open.String() != "10" || c.High.String() != "12" ||
+		c.Low.String() != "9" || c.Close.String() != "11" || c.Volume.String() != "4.5" {
+		t.Errorf("candle = %+v", c)
+	}
+	flat := closed[1]
+	if flat.Time.Unix() != 120 || flat.Open.String() != "11" || flat.Close.String() != "11" || !flat.Volume.IsZero() {
+		t.Errorf("flat candle = %+v", flat)
+	}
+
+	agg.Tick(time.Unix(310, 0))
+	if len(closed) != 4 || closed[2].Time.Unix() != 180 || closed[3].Time.Unix() != 240 {
+		t.Errorf("closed after tick = %+v", closed)
+	}
+	if len(closed) == 4 && closed[3].Close.String() != "13" {
+		t.Errorf("flat candle after tick = %+v", closed[3])
+	}
+	if _, ok := agg.Current(); ok {
+		t.Error("current candle after tick")
+	}
+}
+
+// testKAPI answers K() with candles of 5 minutes around the timestamp.
+type testKAPI struct {
+	PublicAPI
+	timestamp int32
+}
+
+func (a *testKAPI) K(ctx context.Context, market string, opts ...CallOption) ([]*models.Candle, error) {
+	_, o := callOptions(ctx, opts)
+	a.timestamp, _ = o["timestamp"].(int32)
+
+	start := time.Unix(int64(a.timestamp), 0)
+	d := types.MustParseDecimal
+	return []*models.Candle{
+		{Time: start.Add(5 * time.Minute), Open: d("12"), High: d("15"), Low: d("11"), Close: d("14"), Volume: d("2")},
+		{Time: start, Open: d("10"), High: d("12"), Low: d("8"), Close: d("12"), Volume: d("1")},
+		{Time: start.Add(-5 * time.Minute), Open: d("1"), High: d("100"), Low: d("1"), Close: d("1"), Volume: d("9")},
+	}, nil
+}
+
+func TestCandleAggregatorSeed(t *testing.T) {
+	agg, err := NewCandleAggregator(10 * time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &testKAPI{}
+	if err := agg.Seed(context.Background(), api, "btctwd"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(int64(api.timestamp), 0)
+
+	c, ok := agg.Current()
+	if !ok || !c.Time.Equal(start) || c.Open.String() != "10" || c.High.String() != "15" ||
+		c.Low.String() != "8" || c.Close.String() != "14" || c.Volume.String() != "3" {
+		t.Fatalf("seeded candle = %+v, %v", c, ok)
+	}
+
+	d := types.MustParseDecimal
+	// Part of the K() candles.
+	agg.Add(&models.TradeEvent{At: start, Price: d("20"), Volume: d("1")})
+	// Executed after seeding.
+	agg.Add(&models.TradeEvent{At: start.Add(10*time.Minute - time.Second), Price: d("7"), Volume: d("1")})
+
+	c, _ = agg.Current()
+	if c.High.String() != "15" || c.Low.String() != "7" || c.Close.String() != "7" || c.Volume.String() != "4" {
+		t.Errorf("candle after trades = %+v", c)
+	}
+}
+
+func TestCandleAggregatorRun(t *testing.T) {
+	agg, err := NewCandleAggregator(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var closed []models.Candle
+	agg.OnCandle(func(u CandleUpdate) {
+		if u.Closed {
+			closed = append(closed, u.Candle)
+		}
+	})
+
+	d := types.MustParseDecimal
+	trades := make(chan *models.TradeEvent, 3)
+	trades <- &models.TradeEvent{At: time.Unix(60, 0), Price: d("10"), Volume: d("1")}
+	trades <- &models.TradeEvent{At: time.Unix(100, 0), Price: d("11"), Volume: d("1")}
+	trades <- &models.TradeEvent{At: time.Unix(130, 0), Price: d("12"), Volume: d("1")}
+	close(trades)
+
+	if err := agg.Run(context.Background(), trades); err != nil {
+		t.Fatalf("Run() = %v, want nil once the channel is closed", err)
+	}
+	if len(closed) != 1 || closed[0].Close.String() != "11" || closed[0].Volume.String() != "2" {
+		t.Errorf("closed = %+v", closed)
+	}
+	if c, ok := agg.Current(); !ok || c.Time.Unix() != 120 || c.Close.String() != "12" {
+		t.Errorf("current = %+v, %v", c, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := agg.Run(ctx, make(chan *models.TradeEvent)); err != context.Canceled {
+		t.Errorf("Run() = %v, want context canceled", err)
+	}
+}
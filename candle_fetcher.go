@@ -0,0 +1,250 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+// CandlePeriods are the periods supported by K().
+var CandlePeriods = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 4 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 72 * time.Hour, 168 * time.Hour,
+}
+
+const defaultCandlePageSize = 1000
+
+// CandleGap is a range of missing candles, from From included to To excluded.
+type CandleGap struct {
+	From time.Time
+	To   time.Time
+}
+
+// CandleFetcherOption configures a CandleFetcher.
+type CandleFetcherOption func(*CandleFetcher)
+
+// CandlePageSize sets the number of candles requested per K() call,
+// default to 1000, up to 10000.
+func CandlePageSize(size int32) CandleFetcherOption {
+	return func(f *CandleFetcher) {
+		f.pageSize = size
+	}
+}
+
+// FillCandleGaps fills the gaps with flat candles at the previous close
+// price and without volume.
+func FillCandleGaps() CandleFetcherOption {
+	return func(f *CandleFetcher) {
+		f.fillGaps = true
+	}
+}
+
+// CandleFetcher downloads the candles of a time range page by page with K().
+//
+//	fetcher := max.NewCandleFetcher(client, max.FillCandleGaps())
+//	candles, gaps, err := fetcher.Fetch(ctx, "btctwd", time.Hour, from, to)
+//	err = max.WriteCandlesCSV(os.Stdout, candles)
+type CandleFetcher struct {
+	api      PublicAPI
+	pageSize int32
+	fillGaps bool
+}
+
+// NewCandleFetcher returns a CandleFetcher using api.
+func NewCandleFetcher(api PublicAPI, opts ...CandleFetcherOption) *CandleFetcher {
+	f := &CandleFetcher{
+		api:      api,
+		pageSize: defaultCandlePageSize,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Fetch returns the candles of the market starting from from included to
+// to excluded, sorted by time and without duplicates, along with the gaps
+// between them and the bounds of the range. period is one of CandlePeriods.
+//
+// The gaps are filled if FillCandleGaps() is set, and returned anyway. The
+// candles after the current time are not expected, so are not gaps.
+func (f *CandleFetcher) Fetch(ctx context.Context, market string, period time.Duration, from, to time.Time) ([]*models.Candle, []CandleGap, error) {
+	if !supportedCandlePeriod(period) {
+		return nil, nil, fmt.Errorf("max: unsupported candle period %v", period)
+	}
+
+	byTime := make(map[int64]*models.Candle)
+	next := from
+	for next.Before(to) {
+		page, err := f.api.K(ctx, market, PeriodDuration(period), Time(next), Limit(f.pageSize))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var last time.Time
+		for _, c := range page {
+			if !c.Time.Before(from) && c.Time.Before(to) {
+				byTime[c.Time.Unix()] = c
+			}
+			if c.Time.After(last) {
+				last = c.Time
+			}
+		}
+
+		// Stop at the last page, or if the server does not move forward.
+		if len(page) < int(f.pageSize) || !last.After(next) {
+			break
+		}
+		next = last
+	}
+
+	candles := make([]*models.Candle, 0, len(byTime))
+	for _, c := range byTime {
+		candles = append(candles, c)
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Time.Before(candles[j].Time)
+	})
+
+	start, end := candleRange(candles, period, from, to)
+	gaps := candleGaps(candles, period, start, end)
+	if f.fillGaps && len(gaps) > 0 {
+		candles = fillCandleGaps(candles, period, start, end)
+	}
+
+	return candles, gaps, nil
+}
+
+func supportedCandlePeriod(period time.Duration) bool {
+	for _, p := range CandlePeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// candleRange returns the time of the first candle expected from from,
+// aligned on the fetched candles if any, and the end of the expected
+// candles, to or the current time.
+func candleRange(candles []*models.Candle, period time.Duration, from, to time.Time) (time.Time, time.Time) {
+	var start time.Time
+	if len(candles) > 0 {
+		start = candles[0].Time
+		for !start.Add(-period).Before(from) {
+			start = start.Add(-period)
+		}
+	} else if start = from.Truncate(period); start.Before(from) {
+		start = start.Add(period)
+	}
+
+	end := to
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+
+	return start, end
+}
+
+// candleGaps returns the missing periods of the sorted candles from start
+// to end.
+func candleGaps(candles []*models.Candle, period time.Duration, start, end time.Time) []CandleGap {
+	var gaps []CandleGap
+	expected := start
+	for _, c := range candles {
+		if c.Time.After(expected) {
+			gaps = append(gaps, CandleGap{From: expected, To: c.Time})
+		}
+		expected = c.Time.Add(period)
+	}
+	if expected.Before(end) {
+		gaps = append(gaps, CandleGap{From: expected, To: end})
+	}
+
+	return gaps
+}
+
+// fillCandleGaps fills the gaps with flat candles at the previous close
+// price, or at the open price of the first candle before it.
+func fillCandleGaps(candles []*models.Candle, period time.Duration, start, end time.Time) []*models.Candle {
+	if len(candles) == 0 {
+		return candles
+	}
+
+	filled := make([]*models.Candle, 0, len(candles))
+	price := candles[0].Open
+	flat := func(until time.Time) {
+		for ; start.Before(until); start = start.Add(period) {
+			filled = append(filled, &models.Candle{
+				Time:  start,
+				Open:  price,
+				High:  price,
+				Low:   price,
+				Close: price,
+			})
+		}
+	}
+
+	for _, c := range candles {
+		flat(c.Time)
+		filled = append(filled, c)
+		price = c.Close
+		start = c.Time.Add(period)
+	}
+	flat(end)
+
+	return filled
+}
+
+// WriteCandlesCSV writes the candles as CSV, with a header line and
+// timestamps in seconds since Unix epoch.
+func WriteCandlesCSV(w io.Writer, candles []*models.Candle) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"timestamp", "open", "high", "low", "close", "volume"})
+
+	for _, c := range candles {
+		cw.Write([]string{
+			strconv.FormatInt(c.Time.Unix(), 10),
+			c.Open.String(),
+			c.High.String(),
+			c.Low.String(),
+			c.Close.String(),
+			c.Volume.String(),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCandlesJSON writes the candles as a JSON array.
+func WriteCandlesJSON(w io.Writer, candles []*models.Candle) error {
+	if candles == nil {
+		candles = []*models.Candle{}
+	}
+	return json.NewEncoder(w).Encode(candles)
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCandleFetcher(t *testing.T) {
+	// One minute candles from 0 to 9 minutes, without 1, 4 and 5.
+	var all [][]interface{}
+	for i := int64(0); i < 10; i++ {
+		if i == 1 || i == 4 || i == 5 {
+			continue
+		}
+		all = append(all, []interface{}{i * 60, i, i + 1, i, i, 1})
+	}
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		ts, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		// The timestamp is included, so that pages overlap.
+		page := [][]interface{}{}
+		for _, c := range all {
+			if c[0].(int64) >= ts && len(page) < limit {
+				page = append(page, c)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	ctx := context.Background()
+	from, to := time.Unix(60, 0), time.Unix(12*60, 0)
+	f := NewCandleFetcher(c, CandlePageSize(3), FillCandleGaps())
+	candles, gaps, err := f.Fetch(ctx, "btctwd", time.Minute, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls < 3 {
+		t.Errorf("%d K() calls, want pages", calls)
+	}
+	wantGaps := [][2]int64{{60, 120}, {240, 360}, {600, 720}}
+	if len(gaps) != len(wantGaps) {
+		t.Fatalf("gaps = %v, want %v", gaps, wantGaps)
+	}
+	for i, g := range gaps {
+		if g.From.Unix() != wantGaps[i][0] || g.To.Unix() != wantGaps[i][1] {
+			t.Errorf("gap %d = %v, want %v", i, g, wantGaps[i])
+		}
+	}
+	if len(candles) != 11 {
+		t.Fatalf("%d candles, want 11", len(candles))
+	}
+	for i, c := range candles {
+		if c.Time.Unix() != int64(i+1)*60 {
+			t.Errorf("candle %d at %d", i, c.Time.Unix())
+		}
+	}
+	for i, want := range map[int]string{0: "2", 3: "3", 10: "9"} {
+		if filled := candles[i]; filled.Open.String() != want || !filled.Volume.IsZero() {
+			t.Errorf("filled candle %d = %+v, want flat at %s", i, filled, want)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteCandlesCSV(buf, candles[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "timestamp,open,high,low,close,volume\n120,2,3,2,2,1\n"; buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+
+	if _, _, err := f.Fetch(ctx, "btctwd", 3*time.Minute, from, to); err == nil || !strings.Contains(err.Error(), "period") {
+		t.Errorf("Fetch(3m) = %v, want unsupported period", err)
+	}
+}
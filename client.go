@@ -45,6 +45,22 @@ func (c *client) Close() {
 	close(c.stopCh)
 }
 
+// RateLimitBudget returns the number of requests the group can send
+// right now. It returns false if the group is not rate limited,
+// see RateLimiter().
+func (c *client) RateLimitBudget(group RateLimitGroup) (float64, bool) {
+	if c.rateLimiter == nil {
+		return 0, false
+	}
+
+	b, ok := c.rateLimiter.buckets[group]
+	if !ok {
+		return 0, false
+	}
+
+	return b.budget(), true
+}
+
 // Interface check
 var _ PublicAPI = &publicClient{}
 var _ PrivateAPI = &privateClient{}
@@ -53,6 +69,9 @@ type client struct {
 	c              *api.APIClient
 	requestTimeout time.Duration
 	middlewares    []middleware
+	retryPolicy    *RetryPolicy
+	rateLimiter    *rateLimiter
+	validator      *orderValidator
 	stopCh         chan struct{}
 
 	timeDiff       time.Duration
@@ -69,9 +88,21 @@ func (c *client) config() *api.Configuration {
 		s = m(s)
 	}
 
-	c.cfg.HTTPClient = http.DefaultClient
-	c.cfg.HTTPClient.Transport = s
-	c.cfg.HTTPClient.Timeout = c.requestTimeout
+	if c.rateLimiter != nil {
+		s = newRateLimitMiddleware(c.rateLimiter)(s)
+	}
+
+	// Retry wraps the others so that every attempt is throttled, signed and logged.
+	if c.retryPolicy != nil {
+		s = newRetryMiddleware(*c.retryPolicy)(s)
+	}
+
+	s = newResponseMiddleware()(s)
+
+	c.cfg.HTTPClient = &http.Client{
+		Transport: s,
+		Timeout:   c.requestTimeout,
+	}
 
 	return c.cfg
 }
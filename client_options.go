@@ -15,8 +15,11 @@
 package max
 
 import (
+	"context"
 	"log"
 	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
 )
 
 type ClientOption func(*client)
@@ -59,3 +62,38 @@ func Logging(logger *log.Logger) ClientOption {
 		c.middlewares = append(c.middlewares, newLogMiddleware(logger))
 	}
 }
+
+// Retry retries failed requests according to the policy,
+// see DefaultRetryPolicy() for the defaults.
+//
+// Only the methods listed in the policy are retried. The request timeout
+// covers all the attempts of a call.
+func Retry(policy RetryPolicy) ClientOption {
+	return func(c *client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// RateLimiter throttles requests with a token bucket per endpoint group,
+// see DefaultRateLimits() for the defaults.
+//
+// Requests block until a token is available or the context is done. A group
+// is paused when the server answers 429 or runs out of rate limit headers.
+func RateLimiter(limits RateLimits) ClientOption {
+	return func(c *client) {
+		c.rateLimiter = newRateLimiter(limits)
+	}
+}
+
+// ValidateOrders checks the orders of CreateOrder() and CreateOrders()
+// against the market metadata of Markets() before sending them, and returns
+// a *ValidationError for invalid ones.
+//
+// The markets are loaded on first use and reloaded for unknown markets.
+func ValidateOrders(policy PrecisionPolicy) ClientOption {
+	return func(c *client) {
+		c.validator = newOrderValidator(policy, func(ctx context.Context) ([]*models.Market, error) {
+			return c.Markets(ctx)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// config is the content of the config file, a JSON object like
+//
+//	{"access_key": "...", "secret_key": "..."}
+type config struct {
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	// optional REST API base URL and websocket URL
+	APIURL string `json:"api_url"`
+	WSURL  string `json:"ws_url"`
+}
+
+// defaultConfigPath returns $MAX_CONFIG, or ~/.maxctl.json.
+func defaultConfigPath() string {
+	if path := os.Getenv("MAX_CONFIG"); path != "" {
+		return path
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".maxctl.json")
+}
+
+// loadConfig reads the config file, if any, and overrides the keys with
+// the MAX_ACCESS_KEY and MAX_SECRET_KEY environment variables.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, cfg); err != nil {
+				return nil, err
+			}
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	if key := os.Getenv("MAX_ACCESS_KEY"); key != "" {
+		cfg.AccessKey = key
+	}
+	if key := os.Getenv("MAX_SECRET_KEY"); key != "" {
+		cfg.SecretKey = key
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// maxctl is a command line tool for the MAX exchange APIs.
+//
+// The API keys are read from the MAX_ACCESS_KEY and MAX_SECRET_KEY
+// environment variables, or from the config file.
+//
+//	maxctl tickers btctwd ethtwd
+//	maxctl -format csv candles -period 1h -from 2018-06-01T00:00:00Z btctwd
+//	maxctl orders create -side buy -volume 0.01 -price 200000 btctwd
+//	maxctl stream -channels ticker,trade btctwd
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go"
+)
+
+// errUsage is returned after printing the usage of a command.
+var errUsage = errors.New("invalid usage")
+
+// env is what the commands run with.
+type env struct {
+	ctx    context.Context
+	cfg    *config
+	out    *output
+	stderr io.Writer
+	// usage of the running command
+	usage string
+
+	timeout time.Duration
+}
+
+// client returns a REST client, authenticated if private is set.
+func (e *env) client(private bool) (max.API, func(), error) {
+	opts := []max.ClientOption{max.Timeout(e.timeout)}
+	if e.cfg.APIURL != "" {
+		opts = append(opts, max.BasePath(e.cfg.APIURL))
+	}
+	if private {
+		if e.cfg.AccessKey == "" || e.cfg.SecretKey == "" {
+			return nil, nil, errors.New("missing API keys, set MAX_ACCESS_KEY and MAX_SECRET_KEY or the config file")
+		}
+		opts = append(opts, max.AuthToken(e.cfg.AccessKey, e.cfg.SecretKey))
+	}
+
+	c := max.NewClient(opts...)
+	return c, c.Close, nil
+}
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"markets":     {"markets", runMarkets},
+	"tickers":     {"tickers [market...]", runTickers},
+	"depth":       {"depth [-limit n] market", runDepth},
+	"candles":     {"candles [-period 1m] [-from time] [-to time] [-limit n] market", runCandles},
+	"trades":      {"trades [-mine] [-limit n] market", runTrades},
+	"balances":    {"balances [-all]", runBalances},
+	"orders":      {"orders list|create|cancel ...", runOrders},
+	"deposits":    {"deposits [-currency c] [-state s] [-limit n]", runDeposits},
+	"withdrawals": {"withdrawals [-currency c] [-state s] [-limit n]", runWithdrawals},
+	"stream":      {"stream [-channels ticker,orderbook,trade] [-private] [market...]", runStream},
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+
+	switch {
+	case err == errUsage:
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "maxctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("maxctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", defaultConfigPath(), "config file")
+	format := fs.String("format", formatTable, "output format: table, json or csv")
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+	apiURL := fs.String("api", "", "REST API base URL")
+	wsURL := fs.String("ws", "", "websocket URL")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: maxctl [flags] command [flags] [args]")
+		fmt.Fprintln(stderr, "\nCommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(stderr, "  "+commands[name].usage)
+		}
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "maxctl: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+
+	out, err := newOutput(stdout, *format)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *apiURL != "" {
+		cfg.APIURL = *apiURL
+	}
+	if *wsURL != "" {
+		cfg.WSURL = *wsURL
+	}
+
+	e := &env{
+		ctx:     ctx,
+		cfg:     cfg,
+		out:     out,
+		stderr:  stderr,
+		usage:   cmd.usage,
+		timeout: *timeout,
+	}
+	return cmd.run(e, fs.Args()[1:])
+}
+
+// flags returns the flag set of a command, printing its usage on errors.
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: maxctl "+e.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of a command and checks the number of arguments.
+func parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
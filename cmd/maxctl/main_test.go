@@ -0,0 +1,287 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/api"
+	"github.com/maicoin/max-exchange-api-go/maxtest"
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(`[{"id":"btctwd","name":"BTC/TWD","base_unit":"btc","quote_unit":"twd"}]`))
+		case "/api/v2/tickers/btctwd":
+			w.Write([]byte(`{"at":1530000000,"buy":"100","sell":"101","open":"99","last":"100.5","high":"102","low":"98","vol":"2"}`))
+		case "/api/v2/k":
+			w.Write([]byte(`[[60,1,2,0.5,1.5,10]]`))
+		case "/api/v2/depth":
+			w.Write([]byte(`{"timestamp":1530000000,"asks":[["103","1"],["102","2"],["101","3"]],"bids":[["99","4"],["98","5"],["97","6"]]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	exec := func(args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"-config", "", "-api", srv.URL}, args...)
+		err := run(context.Background(), args, &stdout, &stderr)
+		return stdout.String(), err
+	}
+
+	out, err := exec("-format", "csv", "markets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "id,name,base,quote,") || !strings.Contains(out, "btctwd,BTC/TWD,btc,twd,") {
+		t.Errorf("markets csv = %q", out)
+	}
+
+	out, err = exec("tickers", "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "MARKET") || !strings.Contains(lines[1], "100.5") {
+		t.Errorf("tickers table = %q", out)
+	}
+
+	out, err = exec("-format", "json", "candles", "-from", "0", "-to", "120", "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var candles []*models.Candle
+	if err := json.Unmarshal([]byte(out), &candles); err != nil || len(candles) != 1 || candles[0].Close.String() != "1.5" {
+		t.Errorf("candles json = %q, %v", out, err)
+	}
+
+	out, err = exec("-format", "csv", "depth", "-limit", "2", "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "side,price,volume\nask,102,2\nask,101,3\nbid,99,4\nbid,98,5\n"; out != want {
+		t.Errorf("depth csv = %q, want %q", out, want)
+	}
+
+	if _, err := exec("balances"); err == nil || !strings.Contains(err.Error(), "missing API keys") {
+		t.Errorf("balances without keys: %v", err)
+	}
+	if _, err := exec("unknown"); err != errUsage {
+		t.Errorf("unknown command: %v", err)
+	}
+	if _, err := exec("-format", "xml", "markets"); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestRunPrivate(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	fake.SetFixture("GET", "/api/v2/members/me", api.Member{Accounts: []api.Account{
+		{Currency: "btc", Balance: "1.5", Locked: "0.5"},
+		{Currency: "eth", Balance: "0", Locked: "0"},
+	}})
+	fake.SetFixture("GET", "/api/v2/deposits", []api.Deposit{
+		{Currency: "btc", Amount: "1", Fee: "0", Txid: "tx1", State: "accepted", Confirmations: 3},
+	})
+	fake.SetFixture("GET", "/api/v2/withdrawals", []api.Withdrawal{
+		{Uuid: "w1", Currency: "twd", Amount: "100", Fee: "15", State: "done"},
+	})
+	fake.AddOrder(api.Order{Market: "btctwd", Side: "buy", OrdType: "limit", Price: "100", Volume: "1"})
+	fake.AddOrder(api.Order{Market: "ethtwd", Side: "sell", OrdType: "limit", Price: "200", Volume: "2"})
+	fake.AddTrade(api.Trade{Market: "btctwd", Side: "bid", Price: "100", Volume: "0.5", Funds: "50", OrderId: 1})
+	fake.AddTrade(api.Trade{Market: "btctwd", Side: "ask", Price: "101", Volume: "0.1", Funds: "10.1"})
+
+	f, err := ioutil.TempFile("", "maxctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	json.NewEncoder(f).Encode(map[string]string{"access_key": "access", "secret_key": "secret", "api_url": fake.URL})
+	f.Close()
+
+	// The commands run in order against the same server.
+	tests := []struct {
+		args []string
+		want []string
+		not  []string
+		// ord_type of the created order
+		ordType string
+		err     error
+	}{
+		{args: []string{"balances"}, want: []string{"btc,1.5,0.5,2"}, not: []string{"eth"}},
+		{args: []string{"balances", "-all"}, want: []string{"btc,1.5,0.5,2", "eth,0,0,0"}},
+		{args: []string{"orders", "list", "btctwd"}, want: []string{"1,btctwd,buy,limit,100,"}, not: []string{"ethtwd"}},
+		{args: []string{"orders", "create", "-side", "buy", "-volume", "1", "-price", "100", "btctwd"}, want: []string{"3,btctwd,buy,limit,100,"}, ordType: "limit"},
+		{args: []string{"orders", "create", "-side", "sell", "-volume", "1", "btctwd"}, want: []string{"4,btctwd,sell,market,"}, ordType: "market"},
+		{args: []string{"orders", "create", "-side", "sell", "-volume", "1", "-stop", "90", "btctwd"}, want: []string{"5,btctwd,sell,stop_market,"}, ordType: "stop_market"},
+		{args: []string{"orders", "create", "-side", "buy", "-volume", "1", "-price", "100", "-stop", "110", "btctwd"}, want: []string{"6,btctwd,buy,stop_limit,100,110,"}, ordType: "stop_limit"},
+		{args: []string{"orders", "create", "-side", "buy", "-volume", "1", "-type", "market", "btctwd"}, want: []string{"7,btctwd,buy,market,"}, ordType: "market"},
+		{args: []string{"orders", "create", "-side", "hold", "-volume", "1", "btctwd"}, err: errors.New(`invalid side "hold", want buy or sell`)},
+		{args: []string{"orders", "cancel", "1"}, want: []string{"1,btctwd,buy,limit,100,"}, not: []string{"ethtwd"}},
+		{args: []string{"orders", "cancel", "-all", "-market", "ethtwd"}, want: []string{"2,ethtwd,sell,"}, not: []string{"btctwd"}},
+		{args: []string{"orders", "cancel", "-all", "3"}, err: errUsage},
+		{args: []string{"orders", "cancel"}, err: errUsage},
+		{args: []string{"trades", "-mine", "btctwd"}, want: []string{"1,btctwd,"}, not: []string{"2,btctwd,"}},
+		{args: []string{"deposits"}, want: []string{"btc,1,0,accepted,tx1,3,"}},
+		{args: []string{"withdrawals"}, want: []string{"w1,twd,100,15,done,"}},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"-config", f.Name(), "-format", "csv"}, test.args...)
+		err := run(context.Background(), args, &stdout, &stderr)
+		name := strings.Join(test.args, " ")
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("%s: error %v, want %v", name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		out := stdout.String()
+		for _, want := range test.want {
+			if !strings.Contains(out, "\n"+want) {
+				t.Errorf("%s = %q, want %q", name, out, want)
+			}
+		}
+		for _, not := range test.not {
+			if strings.Contains(out, not) {
+				t.Errorf("%s = %q, want no %q", name, out, not)
+			}
+		}
+		if test.ordType != "" {
+			reqs := fake.Requests()
+			if got := reqs[len(reqs)-1].Param("ord_type"); got != test.ordType {
+				t.Errorf("%s: ord_type %q, want %q", name, got, test.ordType)
+			}
+		}
+	}
+	if orders := fake.Orders(); orders[0].State != "cancel" || orders[1].State != "cancel" {
+		t.Errorf("orders 1 and 2 are %s and %s, want cancelled", orders[0].State, orders[1].State)
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	fake := maxtest.NewWSServer("access", "secret")
+	defer fake.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, []string{"-config", "", "-ws", fake.URL, "-format", "json", "stream", "-channels", "trade", "btctwd"}, &stdout, &stderr)
+	}()
+
+	if !fake.WaitSubscribed("trade", "btctwd", 5*time.Second) {
+		t.Fatal("not subscribed")
+	}
+	fake.SendTrade(&models.TradeEvent{
+		At:     time.Unix(1530000000, 0),
+		Market: "btctwd",
+		Price:  types.MustParseDecimal("100"),
+		Volume: types.MustParseDecimal("0.5"),
+	})
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	ev := &models.MarketEvent{}
+	if err := json.Unmarshal(stdout.Bytes(), ev); err != nil || ev.Trade == nil || ev.Trade.Price.String() != "100" {
+		t.Errorf("stream output = %q, %v", stdout.String(), err)
+	}
+}
+
+func TestRunStreamReconnect(t *testing.T) {
+	fake := maxtest.NewWSServer("access", "secret")
+	defer fake.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, []string{"-config", "", "-ws", fake.URL, "-format", "csv", "stream", "-channels", "trade", "btctwd"}, &stdout, &stderr)
+	}()
+
+	if !fake.WaitSubscribed("trade", "btctwd", 5*time.Second) {
+		t.Fatal("not subscribed")
+	}
+	fake.Disconnect()
+	time.Sleep(100 * time.Millisecond)
+	if !fake.WaitSubscribed("trade", "btctwd", 5*time.Second) {
+		t.Fatal("not subscribed again after the disconnection")
+	}
+	fake.SendTrade(&models.TradeEvent{
+		At:     time.Unix(1530000000, 0),
+		Market: "btctwd",
+		Price:  types.MustParseDecimal("100"),
+		Volume: types.MustParseDecimal("0.5"),
+	})
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "trade,btctwd,,100,0.5,") {
+		t.Errorf("stream output = %q", stdout.String())
+	}
+}
+
+func TestRunStreamAuthFailure(t *testing.T) {
+	fake := maxtest.NewWSServer("access", "secret")
+	defer fake.Close()
+
+	f, err := ioutil.TempFile("", "maxctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	json.NewEncoder(f).Encode(map[string]string{"access_key": "access", "secret_key": "wrong", "ws_url": fake.URL})
+	f.Close()
+
+	var stdout, stderr bytes.Buffer
+	err = run(context.Background(), []string{"-config", f.Name(), "-timeout", "500ms", "stream", "-private"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("stream with a wrong secret = %v, want authentication failed", err)
+	}
+}
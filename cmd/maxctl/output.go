@@ -0,0 +1,108 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// output prints the results of the commands. JSON prints the API models,
+// table and CSV print the rows.
+type output struct {
+	w      io.Writer
+	format string
+
+	// streams print their header once
+	headerDone bool
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+
+	return &output{w: w, format: format}, nil
+}
+
+// print prints a result.
+func (o *output) print(v interface{}, header []string, rows [][]string) error {
+	switch o.format {
+	case formatJSON:
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatCSV:
+		cw := csv.NewWriter(o.w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printEvent prints an event of a stream, as a JSON line or a row flushed
+// right away, after the header of the first one.
+func (o *output) printEvent(v interface{}, header []string, row []string) error {
+	switch o.format {
+	case formatJSON:
+		return json.NewEncoder(o.w).Encode(v)
+	case formatCSV:
+		cw := csv.NewWriter(o.w)
+		if !o.headerDone {
+			cw.Write(header)
+			o.headerDone = true
+		}
+		cw.Write(row)
+		cw.Flush()
+		return cw.Error()
+	}
+
+	// The columns of a stream cannot be aligned on the longest value.
+	tw := tabwriter.NewWriter(o.w, 14, 8, 2, ' ', 0)
+	if !o.headerDone {
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+		o.headerDone = true
+	}
+	fmt.Fprintln(tw, strings.Join(row, "\t"))
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,312 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func runBalances(e *env, args []string) error {
+	fs := e.flags("balances")
+	all := fs.Bool("all", false, "include the empty accounts")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	me, err := c.Me(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		accounts []*models.Account
+		rows     [][]string
+	)
+	for _, a := range me.Accounts {
+		if !*all && a.Balance.IsZero() && a.Locked.IsZero() {
+			continue
+		}
+		accounts = append(accounts, a)
+		rows = append(rows, []string{a.Currency, a.Balance.String(), a.Locked.String(), a.Balance.Add(a.Locked).String()})
+	}
+	return e.out.print(accounts, []string{"currency", "balance", "locked", "total"}, rows)
+}
+
+const (
+	ordersListUsage   = "orders list [-limit n] market"
+	ordersCreateUsage = "orders create -side buy|sell -volume v [-price p] [-stop p] [-type t] market"
+	ordersCancelUsage = "orders cancel id... | -all [-market m] [-side s]"
+)
+
+func runOrders(e *env, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			return runOrdersList(e, args[1:])
+		case "create":
+			return runOrdersCreate(e, args[1:])
+		case "cancel":
+			return runOrdersCancel(e, args[1:])
+		}
+	}
+
+	fmt.Fprintln(e.stderr, "Usage:")
+	for _, usage := range []string{ordersListUsage, ordersCreateUsage, ordersCancelUsage} {
+		fmt.Fprintln(e.stderr, "  maxctl "+usage)
+	}
+	return errUsage
+}
+
+func runOrdersList(e *env, args []string) error {
+	e.usage = ordersListUsage
+	fs := e.flags("orders")
+	limit := fs.Int("limit", 100, "number of orders, up to 1000")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	orders, err := c.Orders(e.ctx, fs.Arg(0), max.Limit(int32(*limit)))
+	if err != nil {
+		return err
+	}
+	return printOrders(e, orders)
+}
+
+func runOrdersCreate(e *env, args []string) error {
+	e.usage = ordersCreateUsage
+	fs := e.flags("orders")
+	side := fs.String("side", "", "buy or sell")
+	volume := fs.String("volume", "", "amount to buy or sell")
+	price := fs.String("price", "", "limit price, a market order without it")
+	stop := fs.String("stop", "", "stop price to trigger the order")
+	orderType := fs.String("type", "", "limit, market, stop_limit or stop_market, default from -price and -stop")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	orderSide := types.OrderSide(*side)
+	if orderSide != max.OrderSideBuy && orderSide != max.OrderSideSell {
+		return fmt.Errorf("invalid side %q, want buy or sell", *side)
+	}
+
+	vol, err := types.ParseDecimal(*volume)
+	if err != nil {
+		return fmt.Errorf("invalid volume %q", *volume)
+	}
+
+	var opts []max.CallOption
+	if *price != "" {
+		p, err := types.ParseDecimal(*price)
+		if err != nil {
+			return fmt.Errorf("invalid price %q", *price)
+		}
+		opts = append(opts, max.Price(p))
+	}
+	if *stop != "" {
+		p, err := types.ParseDecimal(*stop)
+		if err != nil {
+			return fmt.Errorf("invalid stop price %q", *stop)
+		}
+		opts = append(opts, max.StopPrice(p))
+	}
+
+	t := types.OrderType(*orderType)
+	if t == "" {
+		switch {
+		case *price != "" && *stop != "":
+			t = max.OrderTypeStopLimit
+		case *stop != "":
+			t = max.OrderTypeStopMarket
+		case *price != "":
+			t = max.OrderTypeLimit
+		default:
+			t = max.OrderTypeMarket
+		}
+	}
+	opts = append(opts, max.OrderType(t))
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	order, err := c.CreateOrder(e.ctx, fs.Arg(0), orderSide, vol, opts...)
+	if err != nil {
+		return err
+	}
+	return printOrders(e, []*models.Order{order})
+}
+
+func runOrdersCancel(e *env, args []string) error {
+	e.usage = ordersCancelUsage
+	fs := e.flags("orders")
+	all := fs.Bool("all", false, "cancel all your open orders")
+	market := fs.String("market", "", "with -all, cancel the orders of the market only")
+	side := fs.String("side", "", "with -all, cancel the buy or sell orders only")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *all == (fs.NArg() > 0) {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var orders []*models.Order
+	if *all {
+		var opts []max.CallOption
+		if *market != "" {
+			opts = append(opts, max.Market(*market))
+		}
+		if *side != "" {
+			opts = append(opts, max.OrderSide(types.OrderSide(*side)))
+		}
+		if orders, err = c.CancelOrders(e.ctx, opts...); err != nil {
+			return err
+		}
+	}
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid order id %q", arg)
+		}
+		order, err := c.CancelOrder(e.ctx, int32(id))
+		if err != nil {
+			return err
+		}
+		orders = append(orders, order)
+	}
+
+	return printOrders(e, orders)
+}
+
+func printOrders(e *env, orders []*models.Order) error {
+	rows := make([][]string, len(orders))
+	for i, o := range orders {
+		rows[i] = []string{
+			strconv.Itoa(int(o.ID)), o.Market, string(o.Side), string(o.OrderType), o.Price.String(),
+			o.StopPrice.String(), o.Volume.String(), o.ExecutedVolume.String(),
+			string(o.State), formatTime(o.CreatedAt),
+		}
+	}
+	return e.out.print(orders, []string{"id", "market", "side", "type", "price", "stop_price", "volume", "executed", "state", "created_at"}, rows)
+}
+
+// historyFlags are the flags of the deposits and withdrawals commands.
+type historyFlags struct {
+	currency *string
+	state    *string
+	limit    *int
+}
+
+func parseHistoryFlags(e *env, name string, args []string) (*historyFlags, error) {
+	fs := e.flags(name)
+	f := &historyFlags{
+		currency: fs.String("currency", "", "currency id, e.g. btc"),
+		state:    fs.String("state", "", "filter by state"),
+		limit:    fs.Int("limit", 50, "number of records, up to 1000"),
+	}
+	if err := parse(fs, args, 0, 0); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *historyFlags) options(state func(string) max.CallOption) []max.CallOption {
+	opts := []max.CallOption{max.Limit(int32(*f.limit))}
+	if *f.currency != "" {
+		opts = append(opts, max.Currency(*f.currency))
+	}
+	if *f.state != "" {
+		opts = append(opts, state(*f.state))
+	}
+	return opts
+}
+
+func runDeposits(e *env, args []string) error {
+	f, err := parseHistoryFlags(e, "deposits", args)
+	if err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	deposits, err := c.Deposits(e.ctx, f.options(func(s string) max.CallOption { return max.DepositState(types.DepositState(s)) })...)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(deposits))
+	for i, d := range deposits {
+		rows[i] = []string{
+			d.Currency, d.Amount.String(), d.Fee.String(), string(d.State), d.TxID,
+			strconv.Itoa(int(d.Confirmations)), formatTime(d.CreatedAt),
+		}
+	}
+	return e.out.print(deposits, []string{"currency", "amount", "fee", "state", "txid", "confirmations", "created_at"}, rows)
+}
+
+func runWithdrawals(e *env, args []string) error {
+	f, err := parseHistoryFlags(e, "withdrawals", args)
+	if err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(true)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	withdrawals, err := c.Withdrawals(e.ctx, f.options(func(s string) max.CallOption { return max.WithdrawalState(types.WithdrawalState(s)) })...)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(withdrawals))
+	for i, w := range withdrawals {
+		rows[i] = []string{
+			w.UUID, w.Currency, w.Amount.String(), w.Fee.String(), string(w.State), w.TxID,
+			formatTime(w.CreatedAt),
+		}
+	}
+	return e.out.print(withdrawals, []string{"uuid", "currency", "amount", "fee", "state", "txid", "created_at"}, rows)
+}
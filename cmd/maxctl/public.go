@@ -0,0 +1,244 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+func runMarkets(e *env, args []string) error {
+	fs := e.flags("markets")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(false)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	markets, err := c.Markets(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(markets))
+	for i, m := range markets {
+		rows[i] = []string{
+			m.Id, m.Name, m.BaseUnit, m.QuoteUnit,
+			strconv.Itoa(int(m.BaseUnitPrecision)),
+			strconv.Itoa(int(m.QuoteUnitPrecision)),
+			strconv.FormatFloat(m.MinBaseAmount, 'f', -1, 64),
+			strconv.FormatFloat(m.MinQuoteAmount, 'f', -1, 64),
+		}
+	}
+	return e.out.print(markets, []string{"id", "name", "base", "quote", "base_precision", "quote_precision", "min_base", "min_quote"}, rows)
+}
+
+func runTickers(e *env, args []string) error {
+	fs := e.flags("tickers")
+	if err := parse(fs, args, 0, -1); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(false)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	tickers := make(models.Tickers)
+	if fs.NArg() == 0 {
+		if tickers, err = c.Tickers(e.ctx); err != nil {
+			return err
+		}
+	}
+	for _, market := range fs.Args() {
+		t, err := c.Ticker(e.ctx, market)
+		if err != nil {
+			return err
+		}
+		tickers[market] = t
+	}
+
+	markets := make([]string, 0, len(tickers))
+	for market := range tickers {
+		markets = append(markets, market)
+	}
+	sort.Strings(markets)
+
+	rows := make([][]string, len(markets))
+	for i, market := range markets {
+		t := tickers[market]
+		rows[i] = []string{
+			market, t.Buy.String(), t.Sell.String(), t.Last.String(),
+			t.Open.String(), t.High.String(), t.Low.String(), t.Volume.String(),
+			formatTime(t.At),
+		}
+	}
+	return e.out.print(tickers, []string{"market", "buy", "sell", "last", "open", "high", "low", "volume", "at"}, rows)
+}
+
+func runDepth(e *env, args []string) error {
+	fs := e.flags("depth")
+	limit := fs.Int("limit", 20, "price levels per side")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(false)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	depth, err := c.Depth(e.ctx, fs.Arg(0), max.Limit(int32(*limit)))
+	if err != nil {
+		return err
+	}
+
+	// Both sides are sorted from the highest price to the lowest, the
+	// asks end and the bids start at the best prices. The limit is only a
+	// hint for the server, keep the best levels.
+	if n := len(depth.Asks); *limit > 0 && n > *limit {
+		depth.Asks = depth.Asks[n-*limit:]
+	}
+	if *limit > 0 && len(depth.Bids) > *limit {
+		depth.Bids = depth.Bids[:*limit]
+	}
+
+	var rows [][]string
+	for _, a := range depth.Asks {
+		rows = append(rows, []string{"ask", a.Price.String(), a.Volume.String()})
+	}
+	for _, b := range depth.Bids {
+		rows = append(rows, []string{"bid", b.Price.String(), b.Volume.String()})
+	}
+	return e.out.print(depth, []string{"side", "price", "volume"}, rows)
+}
+
+func runCandles(e *env, args []string) error {
+	fs := e.flags("candles")
+	period := fs.Duration("period", time.Minute, "candle period, one of 1m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 24h, 72h, 168h")
+	from := fs.String("from", "", "start time, RFC 3339 or seconds since Unix epoch, default to the last -limit candles")
+	to := fs.String("to", "", "end time, default to now")
+	limit := fs.Int("limit", 30, "number of candles without -from")
+	fill := fs.Bool("fill", false, "fill the gaps with flat candles")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	end := time.Now()
+	if *to != "" {
+		t, err := parseTime(*to)
+		if err != nil {
+			return err
+		}
+		end = t
+	}
+	start := end.Add(-time.Duration(*limit) * *period).Truncate(*period)
+	if *from != "" {
+		t, err := parseTime(*from)
+		if err != nil {
+			return err
+		}
+		start = t
+	}
+
+	c, closeFn, err := e.client(false)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var opts []max.CandleFetcherOption
+	if *fill {
+		opts = append(opts, max.FillCandleGaps())
+	}
+	candles, _, err := max.NewCandleFetcher(c, opts...).Fetch(e.ctx, fs.Arg(0), *period, start, end)
+	if err != nil {
+		return err
+	}
+
+	switch e.out.format {
+	case formatCSV:
+		return max.WriteCandlesCSV(e.out.w, candles)
+	case formatJSON:
+		return max.WriteCandlesJSON(e.out.w, candles)
+	}
+
+	rows := make([][]string, len(candles))
+	for i, c := range candles {
+		rows[i] = []string{
+			formatTime(c.Time), c.Open.String(), c.High.String(), c.Low.String(),
+			c.Close.String(), c.Volume.String(),
+		}
+	}
+	return e.out.print(candles, []string{"time", "open", "high", "low", "close", "volume"}, rows)
+}
+
+func runTrades(e *env, args []string) error {
+	fs := e.flags("trades")
+	mine := fs.Bool("mine", false, "your trades instead of the market ones")
+	limit := fs.Int("limit", 50, "number of trades, up to 1000")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	c, closeFn, err := e.client(*mine)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var trades []*models.Trade
+	if *mine {
+		trades, err = c.MyTrades(e.ctx, fs.Arg(0), max.Limit(int32(*limit)))
+	} else {
+		trades, err = c.Trades(e.ctx, fs.Arg(0), max.Limit(int32(*limit)))
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(trades))
+	for i, t := range trades {
+		rows[i] = []string{
+			strconv.Itoa(int(t.ID)), t.Market, t.Side, t.Price.String(), t.Volume.String(),
+			t.Funds.String(), formatTime(t.CreatedAt),
+		}
+	}
+	return e.out.print(trades, []string{"id", "market", "side", "price", "volume", "funds", "created_at"}, rows)
+}
+
+// parseTime parses RFC 3339 times or seconds since Unix epoch.
+func parseTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339 or seconds since Unix epoch", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+// streamHeader is shared by all the events of a stream, the detail column
+// depends on the channel.
+var streamHeader = []string{"channel", "market", "side", "price", "volume", "detail", "at"}
+
+func runStream(e *env, args []string) error {
+	fs := e.flags("stream")
+	channels := fs.String("channels", "ticker,trade", "comma separated public channels: ticker, orderbook, trade")
+	private := fs.Bool("private", false, "stream your balances, orders and trades")
+	if err := parse(fs, args, 0, -1); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 && !*private {
+		fs.Usage()
+		return errUsage
+	}
+
+	opts := []max.WebsocketClientOption{}
+	if e.cfg.WSURL != "" {
+		opts = append(opts, max.WSURL(e.cfg.WSURL))
+	}
+	authenticated := make(chan struct{}, 1)
+	if *private {
+		if e.cfg.AccessKey == "" || e.cfg.SecretKey == "" {
+			return errors.New("missing API keys, set MAX_ACCESS_KEY and MAX_SECRET_KEY or the config file")
+		}
+		opts = append(opts, max.WSAuthToken(e.cfg.AccessKey, e.cfg.SecretKey), max.WSOnStateChange(func(state max.ConnectionState, err error) {
+			if state == max.StateAuthenticated {
+				select {
+				case authenticated <- struct{}{}:
+				default:
+				}
+			}
+		}))
+	}
+
+	ws, err := max.NewWSClientContext(e.ctx, opts...)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	var (
+		marketCh  <-chan *models.MarketEvent
+		accountCh <-chan *models.AccountEvent
+		orderCh   <-chan *models.OrderEvent
+		tradeCh   <-chan *models.Trade
+	)
+
+	if fs.NArg() > 0 {
+		sub, err := ws.SubscribeMarkets(strings.Split(*channels, ","), fs.Args(), make(chan *models.MarketEvent, 100))
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+		marketCh = sub.Chan()
+	}
+	if *private {
+		accountSub, err := ws.SubscribeAccount(make(chan *models.AccountEvent, 100))
+		if err != nil {
+			return err
+		}
+		defer accountSub.Close()
+		accountCh = accountSub.Chan()
+
+		orderSub, err := ws.SubscribeOrders(make(chan *models.OrderEvent, 100))
+		if err != nil {
+			return err
+		}
+		defer orderSub.Close()
+		orderCh = orderSub.Chan()
+
+		tradeSub, err := ws.SubscribeMyTrades(make(chan *models.Trade, 100))
+		if err != nil {
+			return err
+		}
+		defer tradeSub.Close()
+		tradeCh = tradeSub.Chan()
+
+		// The server does not close the connection on a wrong answer
+		// to the challenge, it only sends nothing.
+		select {
+		case <-authenticated:
+		case <-time.After(e.timeout):
+			return errors.New("websocket authentication failed, check the API keys")
+		case <-e.ctx.Done():
+			return nil
+		}
+	}
+
+	// closed returns the error which closed the client and the channels,
+	// nil if it was closed by Close() or the context.
+	closed := func() error {
+		if err := ws.Err(); err != nil && err != max.ErrWSClosed && e.ctx.Err() == nil {
+			return err
+		}
+		return nil
+	}
+
+	for {
+		var (
+			ev  interface{}
+			row []string
+		)
+
+		select {
+		case m, ok := <-marketCh:
+			if !ok {
+				return closed()
+			}
+			ev, row = m, marketEventRow(m)
+		case a, ok := <-accountCh:
+			if !ok {
+				return closed()
+			}
+			total := a.Balance.Add(a.Locked)
+			ev, row = a, []string{"account", a.Currency, "", "", a.Balance.String(), "locked " + a.Locked.String() + ", total " + total.String(), formatTime(a.At)}
+		case o, ok := <-orderCh:
+			if !ok {
+				return closed()
+			}
+			ev, row = o, []string{"order", o.Market, string(o.Side), o.Price.String(), o.Volume.String(),
+				strconv.Itoa(int(o.ID)) + " " + string(o.Update) + ", executed " + o.ExecutedVolume.String(), formatTime(o.At)}
+		case t, ok := <-tradeCh:
+			if !ok {
+				return closed()
+			}
+			ev, row = t, []string{"my_trade", t.Market, t.Side, t.Price.String(), t.Volume.String(),
+				"order " + strconv.Itoa(int(t.OrderID)), formatTime(t.CreatedAt)}
+		case <-ws.Done():
+			return closed()
+		case <-e.ctx.Done():
+			return nil
+		}
+
+		if err := e.out.printEvent(ev, streamHeader, row); err != nil {
+			return err
+		}
+	}
+}
+
+func marketEventRow(m *models.MarketEvent) []string {
+	switch {
+	case m.Ticker != nil:
+		t := m.Ticker
+		return []string{m.Channel, m.Market, "", t.Last.String(), t.Volume.String(),
+			"buy " + t.Buy.String() + ", sell " + t.Sell.String(), formatTime(t.At)}
+	case m.OrderBook != nil:
+		ob := m.OrderBook
+		return []string{m.Channel, m.Market, string(ob.Side), ob.Price.String(), ob.Volume.String(), ob.Action, ""}
+	case m.Trade != nil:
+		t := m.Trade
+		return []string{m.Channel, m.Market, "", t.Price.String(), t.Volume.String(), "", formatTime(t.At)}
+	}
+	return []string{m.Channel, m.Market, "", "", "", "", ""}
+}
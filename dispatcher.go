@@ -0,0 +1,295 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy tells what a subscription does with a new event when its
+// buffer is full, see WithBuffer().
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room in the buffer. It holds back the events
+	// of all the subscriptions until the consumer catches up.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered event.
+	OverflowDropOldest
+	// OverflowDropNewest drops the new event.
+	OverflowDropNewest
+	// OverflowCoalesce replaces the buffered event of the same market with
+	// the new one, e.g. for tickers where only the latest price matters,
+	// and drops the oldest event when the buffer is full.
+	OverflowCoalesce
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowCoalesce:
+		return "coalesce"
+	}
+
+	return "unknown"
+}
+
+const defaultBufferSize = 256
+
+type subscribeOptions struct {
+	size   int
+	policy OverflowPolicy
+}
+
+// SubscribeOption configures a websocket subscription.
+type SubscribeOption func(*subscribeOptions)
+
+// WithBuffer sets the number of events buffered for the subscription
+// channel, and what to do when the buffer is full. Default to the
+// WSBuffer() of the client.
+func WithBuffer(size int, policy OverflowPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.size = size
+		o.policy = policy
+	}
+}
+
+// subscriber delivers the events of a topic in order from its own goroutine,
+// so that a slow consumer only delays its own events.
+type subscriber struct {
+	size   int
+	policy OverflowPolicy
+	// key returns the coalescing key of an event, nil if all the events
+	// share the same key.
+	key func(ev interface{}) string
+	// send passes an event to the consumer, or gives up once done is closed.
+	send func(ev interface{}, done <-chan struct{})
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []interface{}
+	dropped uint64
+	closed  bool
+
+	done   chan struct{}
+	exited chan struct{}
+}
+
+func newSubscriber(o subscribeOptions, send func(interface{}, <-chan struct{})) *subscriber {
+	if o.size <= 0 {
+		o.size = 1
+	}
+
+	s := &subscriber{
+		size:   o.size,
+		policy: o.policy,
+		send:   send,
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	go s.run()
+
+	return s
+}
+
+func (s *subscriber) run() {
+	defer close(s.exited)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+
+		ev := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.cond.Broadcast()
+		s.mu.Unlock()
+
+		s.send(ev, s.done)
+	}
+}
+
+// push queues an event according to the overflow policy.
+func (s *subscriber) push(ev interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if s.policy == OverflowCoalesce {
+		for i, queued := range s.queue {
+			if s.key == nil || s.key(queued) == s.key(ev) {
+				s.queue[i] = ev
+				s.dropped++
+				return
+			}
+		}
+	}
+
+	if len(s.queue) >= s.size {
+		switch s.policy {
+		case OverflowBlock:
+			for len(s.queue) >= s.size && !s.closed {
+				s.cond.Wait()
+			}
+			if s.closed {
+				return
+			}
+		case OverflowDropNewest:
+			s.dropped++
+			return
+		default:
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			s.dropped++
+		}
+	}
+
+	s.queue = append(s.queue, ev)
+	s.cond.Broadcast()
+}
+
+// close stops the delivery and waits for the goroutine to exit, after
+// which the consumer channel can be closed safely.
+func (s *subscriber) close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		s.queue = nil
+		close(s.done)
+		s.cond.Broadcast()
+	}
+	s.mu.Unlock()
+
+	<-s.exited
+}
+
+// Dropped returns the number of events dropped or coalesced.
+func (s *subscriber) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropped
+}
+
+// dispatcher publishes the decoded events to the subscribers of their topic.
+//
+// Publish() is called from the reading goroutine, so the events of a topic
+// are queued, and delivered, in the order they were received. A subscriber
+// can be subscribed to several topics.
+type dispatcher struct {
+	mu     sync.RWMutex
+	topics map[string][]*subscriber
+	// all the subscribers until they are released
+	subs map[*subscriber]struct{}
+	// dropped events of the released subscribers
+	dropped uint64
+}
+
+func newDispatcher() *dispatcher {
+	return &dispatcher{
+		topics: make(map[string][]*subscriber),
+		subs:   make(map[*subscriber]struct{}),
+	}
+}
+
+func (d *dispatcher) Subscribe(topic string, s *subscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.topics[topic] = append(d.topics[topic], s)
+	d.subs[s] = struct{}{}
+}
+
+// Unsubscribe removes the subscriber from the topic, see Release() to stop it.
+func (d *dispatcher) Unsubscribe(topic string, s *subscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	subs := d.topics[topic]
+	for i, sub := range subs {
+		if sub == s {
+			subs = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(subs) == 0 {
+		delete(d.topics, topic)
+	} else {
+		d.topics[topic] = subs
+	}
+}
+
+// Release stops the delivery of an unsubscribed subscriber.
+func (d *dispatcher) Release(s *subscriber) {
+	s.close()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.subs[s]; ok {
+		delete(d.subs, s)
+		atomic.AddUint64(&d.dropped, s.Dropped())
+	}
+}
+
+func (d *dispatcher) Publish(topic string, ev interface{}) {
+	d.mu.RLock()
+	subs := d.topics[topic]
+	d.mu.RUnlock()
+
+	for _, s := range subs {
+		s.push(ev)
+	}
+}
+
+// Close stops the delivery of all the subscribers.
+func (d *dispatcher) Close() {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for s := range d.subs {
+		s.close()
+	}
+}
+
+// Dropped returns the number of events dropped by all the subscribers.
+func (d *dispatcher) Dropped() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	total := atomic.LoadUint64(&d.dropped)
+	for s := range d.subs {
+		total += s.Dropped()
+	}
+
+	return total
+}
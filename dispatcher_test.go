@@ -0,0 +1,106 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intSender(ch chan int) func(interface{}, <-chan struct{}) {
+	return func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(int):
+		case <-done:
+		}
+	}
+}
+
+func TestDispatcherOrdering(t *testing.T) {
+	d := newDispatcher()
+	ch := make(chan int)
+	s := newSubscriber(subscribeOptions{size: 1, policy: OverflowBlock}, intSender(ch))
+	d.Subscribe("topic", s)
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			d.Publish("topic", i)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if got := <-ch; got != i {
+			t.Fatalf("got event %d, want %d", got, i)
+		}
+	}
+
+	d.Unsubscribe("topic", s)
+	d.Release(s)
+	if n := d.Dropped(); n != 0 {
+		t.Errorf("Dropped() = %d, want 0", n)
+	}
+}
+
+func TestSubscriberOverflow(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		want    []int
+		dropped uint64
+	}{
+		{OverflowDropOldest, []int{0, 3, 4}, 2},
+		{OverflowDropNewest, []int{0, 1, 2}, 2},
+		{OverflowCoalesce, []int{0, 4}, 3},
+	}
+
+	for _, test := range tests {
+		ch := make(chan int)
+		s := newSubscriber(subscribeOptions{size: 2, policy: test.policy}, intSender(ch))
+
+		// Wait for the first event to be taken, and blocked on the channel.
+		s.push(0)
+		for {
+			s.mu.Lock()
+			n := len(s.queue)
+			s.mu.Unlock()
+			if n == 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		for i := 1; i <= 4; i++ {
+			s.push(i)
+		}
+
+		var got []int
+		for range test.want {
+			select {
+			case ev := <-ch:
+				got = append(got, ev)
+			case <-time.After(time.Second):
+				t.Fatalf("%v: timeout after %v", test.policy, got)
+			}
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %v, want %v", test.policy, got, test.want)
+		}
+		if n := s.Dropped(); n != test.dropped {
+			t.Errorf("%v: Dropped() = %d, want %d", test.policy, n, test.dropped)
+		}
+		s.close()
+	}
+}
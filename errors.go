@@ -0,0 +1,187 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+// Error codes returned by MAX in the error body.
+const (
+	ErrorCodeAuthorizationFailed = 2001
+	ErrorCodeCreateOrderFailed   = 2002
+	ErrorCodeCancelOrderFailed   = 2003
+	ErrorCodeOrderNotFound       = 2004
+	ErrorCodeIncorrectSignature  = 2005
+	ErrorCodeNonceUsed           = 2006
+	ErrorCodeInvalidNonce        = 2007
+	ErrorCodeInvalidAccessKey    = 2008
+	ErrorCodeDisabledAccessKey   = 2009
+	ErrorCodeExpiredAccessKey    = 2010
+	ErrorCodeOutOfScope          = 2011
+)
+
+// Sentinel errors for errors.Is. An *APIError matches the sentinels
+// reported by its classification helpers.
+var (
+	ErrRateLimited         = errors.New("max: rate limited")
+	ErrAuth                = errors.New("max: authentication failed")
+	ErrInsufficientBalance = errors.New("max: insufficient balance")
+	ErrOrderNotFound       = errors.New("max: order not found")
+)
+
+// APIError is returned by every PublicAPI and PrivateAPI method when
+// the server responds with a non-2xx status.
+type APIError struct {
+	// HTTP status code
+	StatusCode int
+	// MAX error code, 0 if the body carries none
+	Code int
+	// MAX error message
+	Message string
+	// request path, e.g. /api/v2/orders
+	Path string
+	// raw response body
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("max: %s %d: code %d: %s", e.Path, e.StatusCode, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("max: %s %d: %s", e.Path, e.StatusCode, e.Body)
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.rateLimited()
+	case ErrAuth:
+		return e.authError()
+	case ErrInsufficientBalance:
+		return e.insufficientBalance()
+	case ErrOrderNotFound:
+		return e.orderNotFound()
+	}
+
+	return false
+}
+
+func (e *APIError) rateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+func (e *APIError) authError() bool {
+	switch e.Code {
+	case ErrorCodeAuthorizationFailed,
+		ErrorCodeIncorrectSignature,
+		ErrorCodeNonceUsed,
+		ErrorCodeInvalidNonce,
+		ErrorCodeInvalidAccessKey,
+		ErrorCodeDisabledAccessKey,
+		ErrorCodeExpiredAccessKey,
+		ErrorCodeOutOfScope:
+		return true
+	}
+
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+func (e *APIError) insufficientBalance() bool {
+	msg := strings.ToLower(e.Message)
+
+	return strings.Contains(msg, "insufficient") ||
+		strings.Contains(msg, "not enough") ||
+		strings.Contains(msg, "cannot lock funds")
+}
+
+func (e *APIError) orderNotFound() bool {
+	return e.Code == ErrorCodeOrderNotFound
+}
+
+// IsRateLimited reports whether err is caused by a 429 response.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsAuthError reports whether err is caused by missing or invalid credentials.
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuth)
+}
+
+// IsInsufficientBalance reports whether err is an order rejected for lack of funds.
+func IsInsufficientBalance(err error) bool {
+	return errors.Is(err, ErrInsufficientBalance)
+}
+
+// IsOrderNotFound reports whether err is caused by an unknown order id.
+func IsOrderNotFound(err error) bool {
+	return errors.Is(err, ErrOrderNotFound)
+}
+
+type errorJSON struct {
+	Error struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.Path = resp.Request.URL.Path
+	}
+
+	ej := errorJSON{}
+	if err := json.Unmarshal(body, &ej); err == nil {
+		e.Code = ej.Error.Code
+		e.Message = ej.Error.Message
+	}
+
+	return e
+}
+
+// wrapError converts the errors of the generated API client into *APIError.
+func wrapError(err error) error {
+	if re, ok := err.(*api.ResponseError); ok {
+		return newAPIError(re.Response, re.Body)
+	}
+
+	return err
+}
+
+// checkResponse returns an *APIError if resp has a non-2xx status.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	return newAPIError(resp, body)
+}
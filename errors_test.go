@@ -0,0 +1,76 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+func TestAPIError(t *testing.T) {
+	resp := func(status int, path string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Request:    &http.Request{URL: &url.URL{Path: path}},
+		}
+	}
+
+	tests := []struct {
+		resp                *http.Response
+		body                string
+		code                int
+		rateLimited         bool
+		authError           bool
+		insufficientBalance bool
+		orderNotFound       bool
+	}{
+		{resp(429, "/api/v2/tickers"), `Retry later`, 0, true, false, false, false},
+		{resp(401, "/api/v2/members/me"), `{"error":{"code":2005,"message":"Signature is incorrect."}}`, 2005, false, true, false, false},
+		{resp(400, "/api/v2/orders"), `{"error":{"code":2002,"message":"Failed to create order. Reason: cannot lock funds"}}`, 2002, false, false, true, false},
+		{resp(404, "/api/v2/order"), `{"error":{"code":2004,"message":"Order#1 doesn't exist."}}`, 2004, false, false, false, true},
+	}
+
+	for _, test := range tests {
+		err := fmt.Errorf("wrapped: %w", wrapError(&api.ResponseError{Response: test.resp, Body: []byte(test.body)}))
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%v is not an *APIError", err)
+		}
+		if apiErr.Code != test.code {
+			t.Errorf("Code = %d, want %d", apiErr.Code, test.code)
+		}
+		if apiErr.Path != test.resp.Request.URL.Path {
+			t.Errorf("Path = %s, want %s", apiErr.Path, test.resp.Request.URL.Path)
+		}
+		if IsRateLimited(err) != test.rateLimited {
+			t.Errorf("IsRateLimited(%v) = %v", err, !test.rateLimited)
+		}
+		if IsAuthError(err) != test.authError {
+			t.Errorf("IsAuthError(%v) = %v", err, !test.authError)
+		}
+		if IsInsufficientBalance(err) != test.insufficientBalance {
+			t.Errorf("IsInsufficientBalance(%v) = %v", err, !test.insufficientBalance)
+		}
+		if IsOrderNotFound(err) != test.orderNotFound {
+			t.Errorf("IsOrderNotFound(%v) = %v", err, !test.orderNotFound)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/maicoin/max-exchange-api-go"
 	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
 )
 
 func main() {
@@ -63,8 +64,8 @@ func createStopMarketSellOrder(client max.API) {
 	results, err := client.CreateOrders(context.Background(), "mithtwd", []*models.OrderRequest{
 		&models.OrderRequest{
 			Side:      max.OrderSideSell,
-			Volume:    10.0,
-			StopPrice: 15.0,
+			Volume:    types.MustParseDecimal("10"),
+			StopPrice: types.MustParseDecimal("15"),
 			OrderType: max.OrderTypeStopMarket,
 		},
 	})
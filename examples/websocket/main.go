@@ -56,7 +56,7 @@ func main() {
 	}
 	defer tradeSub.Close()
 
-	accountSub, err := client.SubscribeAccount(make(chan models.AccountEvent, 10))
+	accountSub, err := client.SubscribeAccount(make(chan *models.AccountEvent, 10))
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
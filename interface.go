@@ -102,6 +102,7 @@ type PublicAPI interface {
 
 // PrivateAPI provides an interface the private MAX APIs which
 // has authtication requirements and rate limits.
+// Use RateLimiter() to throttle the requests on the client side.
 type PrivateAPI interface {
 	// Me returns user profile and accounts information
 	//
@@ -209,7 +210,7 @@ type PrivateAPI interface {
 	//
 	// Note:
 	//     Use AuthToken() to pass your auth tokens.
-	CreateOrder(context.Context, string, string, types.Volume, ...CallOption) (*models.Order, error)
+	CreateOrder(context.Context, string, types.OrderSide, types.Volume, ...CallOption) (*models.Order, error)
 
 	// CreateOrders creates multiple sell/buy orders.
 	//
@@ -248,7 +249,7 @@ type PrivateAPI interface {
 	// Orders returns your orders.
 	//
 	// Available `CallOption`:
-	//     State(): filter by state, default to 'OrderStateWait'
+	//     OrderState(): filter by state, default to 'OrderStateWait'
 	//     OrderDesc(): use descending order by created time
 	//     OrderAsc(): use ascending order by created time, default value
 	//     Pagination(): do pagination & return metadata in header (default true)
@@ -16,6 +16,8 @@ package max
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/maicoin/max-exchange-api-go/api"
@@ -43,37 +45,37 @@ func (t *tickerJSON) Ticker() (*models.Ticker, error) {
 		return nil, err
 	}
 	ticker.At = time.Unix(at, 0)
-	ticker.Buy, err = t.Buy.Float64()
+	ticker.Buy, err = types.ParsePrice(t.Buy.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Sell, err = t.Sell.Float64()
+	ticker.Sell, err = types.ParsePrice(t.Sell.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Open, err = t.Open.Float64()
+	ticker.Open, err = types.ParsePrice(t.Open.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Last, err = t.Last.Float64()
+	ticker.Last, err = types.ParsePrice(t.Last.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.High, err = t.High.Float64()
+	ticker.High, err = types.ParsePrice(t.High.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Low, err = t.Low.Float64()
+	ticker.Low, err = types.ParsePrice(t.Low.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Volume, err = t.Volume.Float64()
+	ticker.Volume, err = types.ParseVolume(t.Volume.String())
 	if err != nil {
 		return nil, err
 	}
@@ -154,12 +156,12 @@ func (d *depthJSON) Depth() (*models.Depth, error) {
 		for i, x := range n {
 			b := &models.Bargain{}
 
-			b.Price, err = x[0].Float64()
+			b.Price, err = types.ParsePrice(x[0].String())
 			if err != nil {
 				return nil, err
 			}
 
-			b.Volume, err = x[1].Float64()
+			b.Volume, err = types.ParseVolume(x[1].String())
 			if err != nil {
 				return nil, err
 			}
@@ -195,23 +197,23 @@ func (c candleJSON) Candle() (*models.Candle, error) {
 	}
 
 	candle.Time = time.Unix(timestamp, 0)
-	candle.Open, err = c[1].Float64()
+	candle.Open, err = types.ParsePrice(c[1].String())
 	if err != nil {
 		return nil, err
 	}
-	candle.High, err = c[2].Float64()
+	candle.High, err = types.ParsePrice(c[2].String())
 	if err != nil {
 		return nil, err
 	}
-	candle.Low, err = c[3].Float64()
+	candle.Low, err = types.ParsePrice(c[3].String())
 	if err != nil {
 		return nil, err
 	}
-	candle.Close, err = c[4].Float64()
+	candle.Close, err = types.ParsePrice(c[4].String())
 	if err != nil {
 		return nil, err
 	}
-	candle.Volume, err = c[5].Float64()
+	candle.Volume, err = types.ParseVolume(c[5].String())
 	if err != nil {
 		return nil, err
 	}
@@ -256,37 +258,37 @@ func (t *tickerEventJSON) Ticker() (*models.TickerEvent, error) {
 	}
 	ticker.At = time.Unix(0, at*1000000)
 	ticker.Market = t.Market
-	ticker.Buy, err = t.Buy.Float64()
+	ticker.Buy, err = types.ParsePrice(t.Buy.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Sell, err = t.Sell.Float64()
+	ticker.Sell, err = types.ParsePrice(t.Sell.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Open, err = t.Open.Float64()
+	ticker.Open, err = types.ParsePrice(t.Open.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Last, err = t.Last.Float64()
+	ticker.Last, err = types.ParsePrice(t.Last.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.High, err = t.High.Float64()
+	ticker.High, err = types.ParsePrice(t.High.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Low, err = t.Low.Float64()
+	ticker.Low, err = types.ParsePrice(t.Low.String())
 	if err != nil {
 		return nil, err
 	}
 
-	ticker.Volume, err = t.Volume.Float64()
+	ticker.Volume, err = types.ParseVolume(t.Volume.String())
 	if err != nil {
 		return nil, err
 	}
@@ -310,14 +312,474 @@ func (t tradeEventJSON) Trade() (*models.TradeEvent, error) {
 	}
 	trade.At = time.Unix(0, at*1000000)
 	trade.Market = t.Market
-	trade.Price, err = t.Price.Float64()
+	trade.Price, err = types.ParsePrice(t.Price.String())
 	if err != nil {
 		return nil, err
 	}
-	trade.Volume, err = t.Volume.Float64()
+	trade.Volume, err = types.ParseVolume(t.Volume.String())
 	if err != nil {
 		return nil, err
 	}
 
 	return trade, nil
 }
+
+type accountJSON struct {
+	Currency string        `json:"currency,omitempty"`
+	Balance  types.Decimal `json:"balance,omitempty"`
+	Locked   types.Decimal `json:"locked,omitempty"`
+	At       json.Number   `json:"at,omitempty"`
+}
+
+// accountEventJSON is either a single balance update, or a list of them.
+type accountEventJSON struct {
+	accountJSON
+	List []accountJSON `json:"accounts,omitempty"`
+}
+
+func (a *accountEventJSON) Accounts() ([]*models.AccountEvent, error) {
+	list := a.List
+	if list == nil {
+		list = []accountJSON{a.accountJSON}
+	}
+
+	events := make([]*models.AccountEvent, 0, len(list))
+	for _, acc := range list {
+		if acc.Currency == "" {
+			return nil, errors.New("max: account event without currency")
+		}
+
+		at := a.At
+		if acc.At != "" {
+			at = acc.At
+		}
+
+		ev := &models.AccountEvent{
+			Currency: acc.Currency,
+			Balance:  acc.Balance,
+			Locked:   acc.Locked,
+		}
+		if at != "" {
+			ms, err := at.Int64()
+			if err != nil {
+				return nil, err
+			}
+			ev.At = time.Unix(0, ms*int64(time.Millisecond))
+		}
+
+		events = append(events, ev)
+	}
+
+	return events, nil
+}
+
+// orderEventJSON accepts both JSON strings and numbers, like the other stream events.
+type orderEventJSON struct {
+	At              json.Number      `json:"at,omitempty"`
+	ID              json.Number      `json:"id,omitempty"`
+	Side            types.OrderSide  `json:"side,omitempty"`
+	OrderType       types.OrderType  `json:"ord_type,omitempty"`
+	Price           types.Decimal    `json:"price,omitempty"`
+	StopPrice       types.Decimal    `json:"stop_price,omitempty"`
+	AvgPrice        types.Decimal    `json:"avg_price,omitempty"`
+	State           types.OrderState `json:"state,omitempty"`
+	Market          string           `json:"market,omitempty"`
+	CreatedAt       json.Number      `json:"created_at,omitempty"`
+	Volume          types.Decimal    `json:"volume,omitempty"`
+	RemainingVolume types.Decimal    `json:"remaining_volume,omitempty"`
+	ExecutedVolume  types.Decimal    `json:"executed_volume,omitempty"`
+	TradesCount     json.Number      `json:"trades_count,omitempty"`
+}
+
+func (o *orderEventJSON) Order() (*models.OrderEvent, error) {
+	id, err := optionalInt(o.ID)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, errors.New("max: order event without id")
+	}
+
+	at, err := optionalInt(o.At)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := optionalInt(o.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	tradesCount, err := optionalInt(o.TradesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &models.OrderEvent{
+		Order: models.Order{
+			ID:              int32(id),
+			Side:            o.Side,
+			OrderType:       o.OrderType,
+			Price:           o.Price,
+			StopPrice:       o.StopPrice,
+			AvgPrice:        o.AvgPrice,
+			State:           o.State,
+			Market:          o.Market,
+			CreatedAt:       unixTime(int32(createdAt)),
+			Volume:          o.Volume,
+			RemainingVolume: o.RemainingVolume,
+			ExecutedVolume:  o.ExecutedVolume,
+			TradesCount:     int32(tradesCount),
+		},
+	}
+	if at != 0 {
+		ev.At = time.Unix(0, at*int64(time.Millisecond))
+	}
+
+	switch o.State {
+	case types.OrderStateDone:
+		ev.Update = models.OrderUpdateDone
+	case types.OrderStateCancel:
+		ev.Update = models.OrderUpdateCancel
+	case types.OrderStateConvert:
+		ev.Update = models.OrderUpdateTriggered
+	case types.OrderStateWait:
+		if o.ExecutedVolume.IsZero() {
+			ev.Update = models.OrderUpdateNew
+		} else {
+			ev.Update = models.OrderUpdatePartialFill
+		}
+	default:
+		return nil, fmt.Errorf("max: unknown order state %q", o.State)
+	}
+
+	return ev, nil
+}
+
+type myTradeEventJSON struct {
+	At          json.Number   `json:"at,omitempty"`
+	ID          json.Number   `json:"id,omitempty"`
+	Price       types.Decimal `json:"price,omitempty"`
+	Volume      types.Decimal `json:"volume,omitempty"`
+	Funds       types.Decimal `json:"funds,omitempty"`
+	Market      string        `json:"market,omitempty"`
+	CreatedAt   json.Number   `json:"created_at,omitempty"`
+	Side        string        `json:"side,omitempty"`
+	OrderID     json.Number   `json:"order_id,omitempty"`
+	Fee         types.Decimal `json:"fee,omitempty"`
+	FeeCurrency string        `json:"fee_currency,omitempty"`
+}
+
+func (t *myTradeEventJSON) Trade() (*models.Trade, error) {
+	id, err := optionalInt(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, errors.New("max: trade event without id")
+	}
+
+	orderID, err := optionalInt(t.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := optionalInt(t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	trade := &models.Trade{
+		ID:          int32(id),
+		Price:       t.Price,
+		Volume:      t.Volume,
+		Funds:       t.Funds,
+		Market:      t.Market,
+		CreatedAt:   unixTime(int32(createdAt)),
+		Side:        t.Side,
+		OrderID:     int32(orderID),
+		FeeAmount:   t.Fee,
+		FeeCurrency: t.FeeCurrency,
+	}
+
+	// The event time is more precise when available.
+	if at, err := optionalInt(t.At); err == nil && at != 0 {
+		trade.CreatedAt = time.Unix(0, at*int64(time.Millisecond))
+	}
+	if trade.Funds.IsZero() {
+		trade.Funds = trade.Price.Mul(trade.Volume)
+	}
+
+	return trade, nil
+}
+
+func optionalInt(n json.Number) (int64, error) {
+	if n == "" {
+		return 0, nil
+	}
+
+	return n.Int64()
+}
+
+func parseOptionalDecimal(s string) (types.Decimal, error) {
+	if s == "" {
+		return types.Decimal{}, nil
+	}
+
+	return types.ParseDecimal(s)
+}
+
+func unixTime(t int32) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(t), 0)
+}
+
+type tmpOrder api.Order
+
+func (o tmpOrder) Order() (result *models.Order, err error) {
+	result = &models.Order{
+		ID:          o.Id,
+		Side:        types.OrderSide(o.Side),
+		OrderType:   types.OrderType(o.OrdType),
+		State:       types.OrderState(o.State),
+		Market:      o.Market,
+		CreatedAt:   unixTime(o.CreatedAt),
+		TradesCount: o.TradesCount,
+	}
+
+	result.Price, err = parseOptionalDecimal(o.Price)
+	if err != nil {
+		return nil, err
+	}
+	result.StopPrice, err = parseOptionalDecimal(o.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+	result.AvgPrice, err = parseOptionalDecimal(o.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+	result.Volume, err = parseOptionalDecimal(o.Volume)
+	if err != nil {
+		return nil, err
+	}
+	result.RemainingVolume, err = parseOptionalDecimal(o.RemainingVolume)
+	if err != nil {
+		return nil, err
+	}
+	result.ExecutedVolume, err = parseOptionalDecimal(o.ExecutedVolume)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type tmpOrders []api.Order
+
+func (o tmpOrders) Orders() ([]*models.Order, error) {
+	orders := make([]*models.Order, len(o))
+	for i, oo := range o {
+		order, err := tmpOrder(oo).Order()
+		if err != nil {
+			return nil, err
+		}
+
+		orders[i] = order
+	}
+
+	return orders, nil
+}
+
+type tmpOrderBook api.OrderBook
+
+func (o tmpOrderBook) OrderBook() (result *models.OrderBook, err error) {
+	result = &models.OrderBook{}
+
+	result.Asks, err = tmpOrders(o.Asks).Orders()
+	if err != nil {
+		return nil, err
+	}
+	result.Bids, err = tmpOrders(o.Bids).Orders()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type tmpTrade api.Trade
+
+func (t tmpTrade) Trade() (result *models.Trade, err error) {
+	result = &models.Trade{
+		ID:          t.Id,
+		Market:      t.Market,
+		CreatedAt:   unixTime(t.CreatedAt),
+		Side:        t.Side,
+		OrderID:     t.OrderId,
+		FeeCurrency: t.FeeCurrency,
+	}
+
+	result.Price, err = parseOptionalDecimal(t.Price)
+	if err != nil {
+		return nil, err
+	}
+	result.Volume, err = parseOptionalDecimal(t.Volume)
+	if err != nil {
+		return nil, err
+	}
+	result.Funds, err = parseOptionalDecimal(t.Funds)
+	if err != nil {
+		return nil, err
+	}
+	result.FeeAmount, err = parseOptionalDecimal(t.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type tmpTrades []api.Trade
+
+func (t tmpTrades) Trades() ([]*models.Trade, error) {
+	trades := make([]*models.Trade, len(t))
+	for i, tt := range t {
+		trade, err := tmpTrade(tt).Trade()
+		if err != nil {
+			return nil, err
+		}
+
+		trades[i] = trade
+	}
+
+	return trades, nil
+}
+
+type tmpMember api.Member
+
+func (m tmpMember) Member() (*models.Member, error) {
+	result := &models.Member{
+		Sn:                   m.Sn,
+		Name:                 m.Name,
+		Language:             m.Language,
+		PhoneSet:             m.PhoneSet,
+		CountryCode:          m.CountryCode,
+		IdentityNumber:       m.IdentityNumber,
+		InvoiceCarrierID:     m.InvoiceCarrierId,
+		InvoiceCarrierType:   m.InvoiceCarrierType,
+		IsDeleted:            m.IsDeleted,
+		IsFrozen:             m.IsFrozen,
+		IsActivated:          m.IsActivated,
+		ProfileVerified:      m.ProfileVerified,
+		KycApproved:          m.KycApproved,
+		KycState:             m.KycState,
+		PhoneNumber:          m.PhoneNumber,
+		UserAgreementChecked: m.UserAgreementChecked,
+		UserAgreementVersion: m.UserAgreementVersion,
+		Bank:                 m.Bank,
+		Documents:            m.Documents,
+		Email:                m.Email,
+		MemberType:           m.MemberType,
+		Level:                m.Level,
+		Accounts:             make([]*models.Account, len(m.Accounts)),
+	}
+
+	for i, a := range m.Accounts {
+		account := &models.Account{Currency: a.Currency}
+
+		var err error
+		account.Balance, err = parseOptionalDecimal(a.Balance)
+		if err != nil {
+			return nil, err
+		}
+		account.Locked, err = parseOptionalDecimal(a.Locked)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Accounts[i] = account
+	}
+
+	return result, nil
+}
+
+type tmpDeposit api.Deposit
+
+func (d tmpDeposit) Deposit() (result *models.Deposit, err error) {
+	result = &models.Deposit{
+		Currency:      d.Currency,
+		TxID:          d.Txid,
+		CreatedAt:     unixTime(d.CreatedAt),
+		Confirmations: d.Confirmations,
+		UpdatedAt:     unixTime(d.UpdatedAt),
+		State:         types.DepositState(d.State),
+	}
+
+	result.Amount, err = parseOptionalDecimal(d.Amount)
+	if err != nil {
+		return nil, err
+	}
+	result.Fee, err = parseOptionalDecimal(d.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type tmpDeposits []api.Deposit
+
+func (d tmpDeposits) Deposits() ([]*models.Deposit, error) {
+	deposits := make([]*models.Deposit, len(d))
+	for i, dd := range d {
+		deposit, err := tmpDeposit(dd).Deposit()
+		if err != nil {
+			return nil, err
+		}
+
+		deposits[i] = deposit
+	}
+
+	return deposits, nil
+}
+
+type tmpWithdrawal api.Withdrawal
+
+func (w tmpWithdrawal) Withdrawal() (result *models.Withdrawal, err error) {
+	result = &models.Withdrawal{
+		UUID:      w.Uuid,
+		Currency:  w.Currency,
+		TxID:      w.Txid,
+		CreatedAt: unixTime(w.CreatedAt),
+		UpdatedAt: unixTime(w.UpdatedAt),
+		State:     types.WithdrawalState(w.State),
+	}
+
+	result.Amount, err = parseOptionalDecimal(w.Amount)
+	if err != nil {
+		return nil, err
+	}
+	result.Fee, err = parseOptionalDecimal(w.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type tmpWithdrawals []api.Withdrawal
+
+func (w tmpWithdrawals) Withdrawals() ([]*models.Withdrawal, error) {
+	withdrawals := make([]*models.Withdrawal, len(w))
+	for i, ww := range w {
+		withdrawal, err := tmpWithdrawal(ww).Withdrawal()
+		if err != nil {
+			return nil, err
+		}
+
+		withdrawals[i] = withdrawal
+	}
+
+	return withdrawals, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+func TestOrderConversion(t *testing.T) {
+	order, err := tmpOrder(api.Order{
+		Id:              87,
+		Side:            "sell",
+		OrdType:         "market",
+		AvgPrice:        "21499.0",
+		State:           "wait",
+		Market:          "ethtwd",
+		CreatedAt:       1521726960,
+		Volume:          "0.2658",
+		RemainingVolume: "0.1329",
+		ExecutedVolume:  "0.1329",
+	}).Order()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !order.Price.IsZero() {
+		t.Errorf("Price = %s, want 0 for market orders", order.Price)
+	}
+	if order.AvgPrice.String() != "21499.0" {
+		t.Errorf("AvgPrice = %s, want 21499.0", order.AvgPrice)
+	}
+	if !order.CreatedAt.Equal(time.Unix(1521726960, 0)) {
+		t.Errorf("CreatedAt = %v", order.CreatedAt)
+	}
+	if !order.IsOpen() {
+		t.Error("order should be open")
+	}
+	if order.FilledRatio() != 0.5 {
+		t.Errorf("FilledRatio = %v, want 0.5", order.FilledRatio())
+	}
+
+	if _, err := tmpOrder(api.Order{Volume: "abc"}).Order(); err == nil {
+		t.Error("invalid volume should fail")
+	}
+}
+
+func TestTradeConversion(t *testing.T) {
+	trade, err := tmpTrade(api.Trade{
+		Id:          68444,
+		Price:       "21499.0",
+		Volume:      "0.2658",
+		Funds:       "5714.4",
+		Market:      "ethtwd",
+		CreatedAt:   1521726960,
+		Side:        "bid",
+		OrderId:     87,
+		Fee:         "0.0001",
+		FeeCurrency: "eth",
+	}).Trade()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fee, currency := trade.Fee()
+	if fee.String() != "0.0001" || currency != "eth" {
+		t.Errorf("Fee = %s %s, want 0.0001 eth", fee, currency)
+	}
+	if trade.Funds.String() != "5714.4" {
+		t.Errorf("Funds = %s, want 5714.4", trade.Funds)
+	}
+}
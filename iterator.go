@@ -0,0 +1,385 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+const (
+	// HeaderTotal is the pagination header carrying the total number of records.
+	HeaderTotal = "Total"
+
+	defaultPageSize     int32 = 100
+	maxRateLimitRetries       = 3
+)
+
+// pageFetcher loads a page with the given options into the typed buffer of an
+// iterator, and returns the number of loaded records.
+type pageFetcher func(ctx context.Context, opts []CallOption) (int, *http.Response, error)
+
+// iterator walks the records of a listing call page by page.
+type iterator struct {
+	ctx   context.Context
+	opts  []CallOption
+	fetch pageFetcher
+	limit int32
+
+	// cursor returns the options of the following page, given the size of the current one.
+	cursor func(n int) []CallOption
+
+	pos   int
+	size  int
+	total int
+	known bool
+	done  bool
+	err   error
+}
+
+func newIterator(ctx context.Context, opts []CallOption, fetch pageFetcher) *iterator {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	limit, ok := o["limit"].(int32)
+	if !ok {
+		limit = defaultPageSize
+	}
+
+	it := &iterator{
+		ctx:   ctx,
+		opts:  opts,
+		fetch: fetch,
+		limit: limit,
+		pos:   -1,
+	}
+
+	page, ok := o["page"].(int32)
+	if !ok {
+		page = 1
+	}
+	it.cursor = func(int) []CallOption {
+		p := page
+		page++
+		return []CallOption{Pagination(true), Page(p)}
+	}
+
+	return it
+}
+
+func (it *iterator) next() bool {
+	it.pos++
+	if it.pos < it.size {
+		return true
+	}
+
+	if it.done || it.err != nil {
+		return false
+	}
+
+	opts := append(append([]CallOption{}, it.opts...), it.cursor(it.size)...)
+	opts = append(opts, Limit(it.limit))
+
+	n, resp, err := it.fetchPage(opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if resp != nil && !it.known {
+		if total, err := strconv.Atoi(resp.Header.Get(HeaderTotal)); err == nil {
+			it.total = total
+			it.known = true
+		}
+	}
+
+	it.pos = 0
+	it.size = n
+	if n < int(it.limit) {
+		it.done = true
+	}
+
+	return n > 0
+}
+
+// fetchPage loads a page, waiting and retrying when the server answers 429.
+// The Retry-After delays are capped like the rate limit penalty.
+func (it *iterator) fetchPage(opts []CallOption) (int, *http.Response, error) {
+	wait := time.Second
+
+	for retries := 0; ; retries++ {
+		n, resp, err := it.fetch(it.ctx, opts)
+		if err == nil || !IsRateLimited(err) || retries >= maxRateLimitRetries {
+			return n, resp, err
+		}
+
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = RetryPolicy{MaxBackoff: maxRateLimitPenalty}.capRetryAfter(after)
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-it.ctx.Done():
+			timer.Stop()
+			return 0, nil, it.ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Total returns the total number of records reported by the pagination headers.
+func (it *iterator) Total() (int, bool) {
+	return it.total, it.known
+}
+
+// byTradeID makes the iterator walk trades by id rather than by page, which
+// does not skip nor repeat trades when new ones are executed meanwhile. Page()
+// and Offset() only apply to the first page, the next ones start after the
+// last trade id.
+func (it *iterator) byTradeID(lastID func() int32) {
+	o := defaultOptions()
+	for _, opt := range it.opts {
+		opt(o)
+	}
+	asc := o["order_by"] == orderAscending
+
+	it.cursor = func(n int) []CallOption {
+		if n == 0 {
+			return []CallOption{Pagination(true)}
+		}
+		if asc {
+			return []CallOption{withoutOptions("page", "offset"), From(lastID())}
+		}
+		return []CallOption{withoutOptions("page", "offset"), To(lastID())}
+	}
+}
+
+// withoutOptions removes the parameters set by the previous options.
+func withoutOptions(keys ...string) CallOption {
+	return func(opt map[string]interface{}) {
+		for _, k := range keys {
+			delete(opt, k)
+		}
+	}
+}
+
+// TradeIterator walks trades, see MyTradesIter() and TradesIter().
+//
+//	it := client.MyTradesIter(ctx, "btctwd")
+//	for it.Next() {
+//	    trade := it.Trade()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type TradeIterator struct {
+	*iterator
+	trades []*models.Trade
+}
+
+// Next advances to the next trade, fetching a new page if needed.
+func (it *TradeIterator) Next() bool {
+	return it.next()
+}
+
+// Trade returns the current trade.
+func (it *TradeIterator) Trade() *models.Trade {
+	return it.trades[it.pos]
+}
+
+func newTradeIterator(ctx context.Context, opts []CallOption, fetch func(context.Context, map[string]interface{}) ([]*models.Trade, *http.Response, error)) *TradeIterator {
+	it := &TradeIterator{}
+	it.iterator = newIterator(ctx, opts, func(ctx context.Context, opts []CallOption) (int, *http.Response, error) {
+		ctx, o := callOptions(ctx, opts)
+
+		trades, resp, err := fetch(ctx, o)
+		it.trades = trades
+		return len(trades), resp, err
+	})
+	it.byTradeID(func() int32 {
+		return it.trades[len(it.trades)-1].ID
+	})
+
+	return it
+}
+
+// OrderIterator walks orders, see OrdersIter().
+type OrderIterator struct {
+	*iterator
+	orders []*models.Order
+}
+
+// Next advances to the next order, fetching a new page if needed.
+func (it *OrderIterator) Next() bool {
+	return it.next()
+}
+
+// Order returns the current order.
+func (it *OrderIterator) Order() *models.Order {
+	return it.orders[it.pos]
+}
+
+// DepositIterator walks deposits, see DepositsIter().
+type DepositIterator struct {
+	*iterator
+	deposits []*models.Deposit
+}
+
+// Next advances to the next deposit, fetching a new page if needed.
+func (it *DepositIterator) Next() bool {
+	return it.next()
+}
+
+// Deposit returns the current deposit.
+func (it *DepositIterator) Deposit() *models.Deposit {
+	return it.deposits[it.pos]
+}
+
+// WithdrawalIterator walks withdrawals, see WithdrawalsIter().
+type WithdrawalIterator struct {
+	*iterator
+	withdrawals []*models.Withdrawal
+}
+
+// Next advances to the next withdrawal, fetching a new page if needed.
+func (it *WithdrawalIterator) Next() bool {
+	return it.next()
+}
+
+// Withdrawal returns the current withdrawal.
+func (it *WithdrawalIterator) Withdrawal() *models.Withdrawal {
+	return it.withdrawals[it.pos]
+}
+
+// TradesIter returns an iterator over the trades of the market, walking
+// backward by trade id (or forward with OrderAsc()).
+//
+// Accepts the same `CallOption` as Trades(), Limit() sets the page size.
+func (c *publicClient) TradesIter(ctx context.Context, market string, opts ...CallOption) *TradeIterator {
+	return newTradeIterator(ctx, opts, func(ctx context.Context, o map[string]interface{}) ([]*models.Trade, *http.Response, error) {
+		trades, resp, err := c.c.PublicApi.GetApiV2Trades(ctx, market, o)
+		if err != nil {
+			return nil, resp, wrapError(err)
+		}
+
+		results, err := tmpTrades(trades).Trades()
+		return results, resp, err
+	})
+}
+
+// MyTradesIter returns an iterator over your trades of the market, walking
+// backward by trade id (or forward with OrderAsc()).
+//
+// Accepts the same `CallOption` as MyTrades(), Limit() sets the page size.
+//
+// Note:
+//
+//	Use AuthToken() to pass your auth tokens.
+func (c *privateClient) MyTradesIter(ctx context.Context, market string, opts ...CallOption) *TradeIterator {
+	return newTradeIterator(ctx, opts, func(ctx context.Context, o map[string]interface{}) ([]*models.Trade, *http.Response, error) {
+		trades, resp, err := c.c.PrivateApi.GetApiV2TradesMy(ctx, "", "", "", market, o)
+		if err != nil {
+			return nil, resp, wrapError(err)
+		}
+
+		results, err := tmpTrades(trades).Trades()
+		return results, resp, err
+	})
+}
+
+// OrdersIter returns an iterator over your orders of the market, page by page.
+//
+// Accepts the same `CallOption` as Orders(), Limit() sets the page size.
+//
+// Note:
+//
+//	Use AuthToken() to pass your auth tokens.
+func (c *privateClient) OrdersIter(ctx context.Context, market string, opts ...CallOption) *OrderIterator {
+	it := &OrderIterator{}
+	it.iterator = newIterator(ctx, opts, func(ctx context.Context, opts []CallOption) (int, *http.Response, error) {
+		ctx, o := callOptions(ctx, opts)
+
+		orders, resp, err := c.c.PrivateApi.GetApiV2Orders(ctx, "", "", "", market, o)
+		if err != nil {
+			return 0, resp, wrapError(err)
+		}
+
+		it.orders, err = tmpOrders(orders).Orders()
+		return len(it.orders), resp, err
+	})
+
+	return it
+}
+
+// DepositsIter returns an iterator over your deposits, page by page.
+//
+// Accepts the same `CallOption` as Deposits(), Limit() sets the page size.
+//
+// Note:
+//
+//	Use AuthToken() to pass your auth tokens.
+func (c *privateClient) DepositsIter(ctx context.Context, opts ...CallOption) *DepositIterator {
+	it := &DepositIterator{}
+	it.iterator = newIterator(ctx, opts, func(ctx context.Context, opts []CallOption) (int, *http.Response, error) {
+		ctx, o := callOptions(ctx, opts)
+
+		deposits, resp, err := c.c.PrivateApi.GetApiV2Deposits(ctx, "", "", "", o)
+		if err != nil {
+			return 0, resp, wrapError(err)
+		}
+
+		it.deposits, err = tmpDeposits(deposits).Deposits()
+		return len(it.deposits), resp, err
+	})
+
+	return it
+}
+
+// WithdrawalsIter returns an iterator over your withdrawals, page by page.
+//
+// Accepts the same `CallOption` as Withdrawals(), Limit() sets the page size.
+//
+// Note:
+//
+//	Use AuthToken() to pass your auth tokens.
+func (c *privateClient) WithdrawalsIter(ctx context.Context, opts ...CallOption) *WithdrawalIterator {
+	it := &WithdrawalIterator{}
+	it.iterator = newIterator(ctx, opts, func(ctx context.Context, opts []CallOption) (int, *http.Response, error) {
+		ctx, o := callOptions(ctx, opts)
+
+		withdrawals, resp, err := c.c.PrivateApi.GetApiV2Withdrawals(ctx, "", "", "", o)
+		if err != nil {
+			return 0, resp, wrapError(err)
+		}
+
+		it.withdrawals, err = tmpWithdrawals(withdrawals).Withdrawals()
+		return len(it.withdrawals), resp, err
+	})
+
+	return it
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+func TestMyTradesIter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		to := 8
+		if v := q.Get("to"); v != "" {
+			to, _ = strconv.Atoi(v)
+		} else {
+			w.Header().Set(HeaderTotal, "7")
+		}
+
+		trades := []api.Trade{}
+		for id := to - 1; id > 0 && len(trades) < limit; id-- {
+			trades = append(trades, api.Trade{Id: int32(id), Price: "1.0", Volume: "2.0"})
+		}
+		json.NewEncoder(w).Encode(trades)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"))
+	defer c.Close()
+
+	it := c.MyTradesIter(context.Background(), "btctwd", Limit(3))
+	var ids []int32
+	for it.Next() {
+		ids = append(ids, it.Trade().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 7 || ids[0] != 7 || ids[6] != 1 {
+		t.Errorf("ids = %v, want 7 to 1", ids)
+	}
+	if total, ok := it.Total(); !ok || total != 7 {
+		t.Errorf("Total() = %d, %v, want 7", total, ok)
+	}
+}
+
+func TestMyTradesIterOffset(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, r.URL.RawQuery)
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		to := 11
+		if v := q.Get("to"); v != "" {
+			to, _ = strconv.Atoi(v)
+		}
+
+		trades := []api.Trade{}
+		for id := to - 1 - offset; id > 0 && len(trades) < limit; id-- {
+			trades = append(trades, api.Trade{Id: int32(id), Price: "1.0", Volume: "2.0"})
+		}
+		json.NewEncoder(w).Encode(trades)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"))
+	defer c.Close()
+
+	it := c.MyTradesIter(context.Background(), "btctwd", Limit(3), Offset(2))
+	var ids []int32
+	for it.Next() {
+		ids = append(ids, it.Trade().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 8 || ids[0] != 8 || ids[7] != 1 {
+		t.Errorf("ids = %v, want 8 to 1", ids)
+	}
+	for _, q := range queries[1:] {
+		if v, _ := url.ParseQuery(q); v.Get("offset") != "" || v.Get("page") != "" {
+			t.Errorf("query %q, want no page nor offset after the first page", q)
+		}
+	}
+}
+
+func TestOrdersIter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		orders := []api.Order{}
+		if page <= 2 {
+			orders = append(orders, api.Order{Id: int32(page*2 - 1)}, api.Order{Id: int32(page * 2)})
+		}
+		json.NewEncoder(w).Encode(orders)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"))
+	defer c.Close()
+
+	it := c.OrdersIter(context.Background(), "btctwd", Limit(2))
+	n := 0
+	for it.Next() {
+		n++
+		if it.Order().ID != int32(n) {
+			t.Errorf("order %d has id %d", n, it.Order().ID)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("got %d orders, want 4", n)
+	}
+	if _, ok := it.Total(); ok {
+		t.Error("Total() should be unknown without pagination headers")
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// MarketChange describes the markets listed or delisted between two refreshes.
+type MarketChange struct {
+	Added   []*models.Market
+	Removed []*models.Market
+}
+
+// MarketRegistry caches the market and currency metadata of Markets() and
+// Currencies(), and refreshes them periodically.
+//
+//	registry, err := max.NewMarketRegistry(ctx, client, 10*time.Minute)
+//	m, ok := registry.MarketBySymbol("BTC/TWD")
+//	price, err := registry.FormatPrice(m.Id, p)
+type MarketRegistry struct {
+	api PublicAPI
+
+	mu         sync.RWMutex
+	markets    map[string]*models.Market
+	currencies map[string]*models.Currency
+	handlers   []func(MarketChange)
+	loaded     bool
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMarketRegistry loads the metadata, and refreshes them every period
+// in the background if period is positive. Call Close() to stop refreshing.
+func NewMarketRegistry(ctx context.Context, api PublicAPI, period time.Duration) (*MarketRegistry, error) {
+	r := &MarketRegistry{
+		api:        api,
+		markets:    make(map[string]*models.Market),
+		currencies: make(map[string]*models.Currency),
+		stopCh:     make(chan struct{}),
+	}
+
+	if err := r.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if period > 0 {
+		go r.refresher(period)
+	}
+
+	return r, nil
+}
+
+// Close stops the background refresh.
+func (r *MarketRegistry) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+}
+
+func (r *MarketRegistry) refresher(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), period)
+			r.Refresh(ctx)
+			cancel()
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Refresh reloads the metadata, and notifies the OnChange() handlers
+// when markets are added or removed.
+func (r *MarketRegistry) Refresh(ctx context.Context) error {
+	markets, err := r.api.Markets(ctx)
+	if err != nil {
+		return err
+	}
+
+	currencies, err := r.api.Currencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	marketMap := make(map[string]*models.Market, len(markets))
+	for _, m := range markets {
+		marketMap[m.Id] = m
+	}
+
+	currencyMap := make(map[string]*models.Currency, len(currencies))
+	for _, c := range currencies {
+		currencyMap[c.Id] = c
+	}
+
+	r.mu.Lock()
+	change := MarketChange{}
+	initial := !r.loaded
+	for id, m := range marketMap {
+		if _, ok := r.markets[id]; !ok {
+			change.Added = append(change.Added, m)
+		}
+	}
+	for id, m := range r.markets {
+		if _, ok := marketMap[id]; !ok {
+			change.Removed = append(change.Removed, m)
+		}
+	}
+	r.markets = marketMap
+	r.currencies = currencyMap
+	r.loaded = true
+	handlers := r.handlers
+	r.mu.Unlock()
+
+	if initial || (len(change.Added) == 0 && len(change.Removed) == 0) {
+		return nil
+	}
+
+	sortMarkets(change.Added)
+	sortMarkets(change.Removed)
+	for _, h := range handlers {
+		h(change)
+	}
+
+	return nil
+}
+
+// OnChange registers a handler called after a refresh adds or removes markets.
+func (r *MarketRegistry) OnChange(h func(MarketChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers = append(r.handlers, h)
+}
+
+// Market returns the market of the given id, e.g. btctwd.
+func (r *MarketRegistry) Market(id string) (*models.Market, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.markets[id]
+	return m, ok
+}
+
+// MarketBySymbol returns the market of a symbol like "BTC/TWD", "btc-twd" or "btctwd".
+func (r *MarketRegistry) MarketBySymbol(symbol string) (*models.Market, bool) {
+	id := strings.Map(func(c rune) rune {
+		switch c {
+		case '/', '-', '_', ' ':
+			return -1
+		}
+		return c
+	}, strings.ToLower(symbol))
+
+	return r.Market(id)
+}
+
+// MarketByUnits returns the market trading base against quote, e.g. btc and twd.
+func (r *MarketRegistry) MarketByUnits(base, quote string) (*models.Market, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.markets {
+		if strings.EqualFold(m.BaseUnit, base) && strings.EqualFold(m.QuoteUnit, quote) {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// Markets returns all the markets sorted by id.
+func (r *MarketRegistry) Markets() []*models.Market {
+	return r.filter(func(*models.Market) bool { return true })
+}
+
+// MarketsByBase returns the markets of the given base unit sorted by id.
+func (r *MarketRegistry) MarketsByBase(base string) []*models.Market {
+	return r.filter(func(m *models.Market) bool {
+		return strings.EqualFold(m.BaseUnit, base)
+	})
+}
+
+// MarketsByQuote returns the markets of the given quote unit sorted by id.
+func (r *MarketRegistry) MarketsByQuote(quote string) []*models.Market {
+	return r.filter(func(m *models.Market) bool {
+		return strings.EqualFold(m.QuoteUnit, quote)
+	})
+}
+
+func (r *MarketRegistry) filter(match func(*models.Market) bool) []*models.Market {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*models.Market
+	for _, m := range r.markets {
+		if match(m) {
+			results = append(results, m)
+		}
+	}
+	sortMarkets(results)
+
+	return results
+}
+
+// Currency returns the currency of the given id, e.g. btc.
+func (r *MarketRegistry) Currency(id string) (*models.Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.currencies[strings.ToLower(id)]
+	return c, ok
+}
+
+// Currencies returns all the currencies sorted by id.
+func (r *MarketRegistry) Currencies() []*models.Currency {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.Currency, 0, len(r.currencies))
+	for _, c := range r.currencies {
+		results = append(results, c)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Id < results[j].Id
+	})
+
+	return results
+}
+
+// FormatPrice formats a price with the quote unit precision of the market.
+func (r *MarketRegistry) FormatPrice(market string, price types.Price) (string, error) {
+	m, ok := r.Market(market)
+	if !ok {
+		return "", fmt.Errorf("max: unknown market %s", market)
+	}
+
+	return price.StringFixed(m.QuoteUnitPrecision), nil
+}
+
+// FormatVolume formats a volume with the base unit precision of the market.
+func (r *MarketRegistry) FormatVolume(market string, volume types.Volume) (string, error) {
+	m, ok := r.Market(market)
+	if !ok {
+		return "", fmt.Errorf("max: unknown market %s", market)
+	}
+
+	return volume.StringFixed(m.BaseUnitPrecision), nil
+}
+
+func sortMarkets(markets []*models.Market) {
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].Id < markets[j].Id
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestMarketRegistry(t *testing.T) {
+	markets := `[{"id":"btctwd","base_unit":"btc","base_unit_precision":4,"quote_unit":"twd","quote_unit_precision":1},
+		{"id":"ethtwd","base_unit":"eth","base_unit_precision":4,"quote_unit":"twd","quote_unit_precision":1}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(markets))
+		case "/api/v2/currencies":
+			w.Write([]byte(`[{"id":"btc","precision":8},{"id":"twd","precision":0}]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	ctx := context.Background()
+	r, err := NewMarketRegistry(ctx, c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if m, ok := r.MarketBySymbol("BTC/TWD"); !ok || m.Id != "btctwd" {
+		t.Errorf("MarketBySymbol(BTC/TWD) = %v, %v", m, ok)
+	}
+	if m, ok := r.MarketByUnits("ETH", "twd"); !ok || m.Id != "ethtwd" {
+		t.Errorf("MarketByUnits(ETH, twd) = %v, %v", m, ok)
+	}
+	if got := r.MarketsByQuote("twd"); len(got) != 2 || got[0].Id != "btctwd" {
+		t.Errorf("MarketsByQuote(twd) = %v", got)
+	}
+	if c, ok := r.Currency("BTC"); !ok || c.Precision != 8 {
+		t.Errorf("Currency(BTC) = %v, %v", c, ok)
+	}
+	if s, _ := r.FormatPrice("btctwd", types.MustParseDecimal("100000.25")); s != "100000.3" {
+		t.Errorf("FormatPrice = %s, want 100000.3", s)
+	}
+	if s, _ := r.FormatVolume("btctwd", types.MustParseDecimal("0.5")); s != "0.5000" {
+		t.Errorf("FormatVolume = %s, want 0.5000", s)
+	}
+
+	var changes []MarketChange
+	r.OnChange(func(change MarketChange) {
+		changes = append(changes, change)
+	})
+
+	markets = `[{"id":"btctwd"},{"id":"usdttwd"}]`
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want 1", len(changes))
+	}
+	if added := changes[0].Added; len(added) != 1 || added[0].Id != "usdttwd" {
+		t.Errorf("Added = %v", added)
+	}
+	if removed := changes[0].Removed; len(removed) != 1 || removed[0].Id != "ethtwd" {
+		t.Errorf("Removed = %v", removed)
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maxtest
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	max "github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+type handler func(s *Server, req *Request) (int, interface{})
+
+type route struct {
+	method  string
+	pattern string
+	private bool
+	handler handler
+}
+
+var routes = []route{
+	{http.MethodGet, "/api/v2/markets", false, static(sampleMarkets)},
+	{http.MethodGet, "/api/v2/currencies", false, static(sampleCurrencies)},
+	{http.MethodGet, "/api/v2/tickers/{market}", false, (*Server).ticker},
+	{http.MethodGet, "/api/v2/tickers", false, (*Server).tickers},
+	{http.MethodGet, "/api/v2/order_book", false, static(map[string]interface{}{"asks": []interface{}{}, "bids": []interface{}{}})},
+	{http.MethodGet, "/api/v2/depth", false, (*Server).depth},
+	{http.MethodGet, "/api/v2/trades", false, (*Server).listTrades},
+	{http.MethodGet, "/api/v2/k", false, static([]interface{}{})},
+	{http.MethodGet, "/api/v2/timestamp", false, (*Server).timestamp},
+
+	{http.MethodGet, "/api/v2/members/me", true, static(sampleMember)},
+	{http.MethodGet, "/api/v2/deposits", true, static([]interface{}{})},
+	{http.MethodGet, "/api/v2/deposit", true, notFound("deposit")},
+	{http.MethodGet, "/api/v2/deposit_address", true, static([]interface{}{})},
+	{http.MethodGet, "/api/v2/deposit_addresses", true, static([]interface{}{})},
+	{http.MethodPost, "/api/v2/deposit_addresses", true, static([]interface{}{})},
+	{http.MethodGet, "/api/v2/withdrawals", true, static([]interface{}{})},
+	{http.MethodGet, "/api/v2/withdrawal", true, notFound("withdrawal")},
+	{http.MethodGet, "/api/v2/trades/my", true, (*Server).listMyTrades},
+	{http.MethodGet, "/api/v2/orders", true, (*Server).listOrders},
+	{http.MethodPost, "/api/v2/orders", true, (*Server).createOrder},
+	{http.MethodPost, "/api/v2/orders/multi", true, (*Server).createOrders},
+	{http.MethodPost, "/api/v2/orders/clear", true, (*Server).clearOrders},
+	{http.MethodGet, "/api/v2/order", true, (*Server).getOrder},
+	{http.MethodPost, "/api/v2/order/delete", true, (*Server).cancelOrder},
+}
+
+// Sample data of the routes without fixtures.
+var (
+	sampleMarkets = []api.Market{
+		{Id: "btctwd", Name: "BTC/TWD", BaseUnit: "btc", BaseUnitPrecision: 6, QuoteUnit: "twd", QuoteUnitPrecision: 1, MinBaseAmount: 0.0004, MinQuoteAmount: 250},
+		{Id: "ethtwd", Name: "ETH/TWD", BaseUnit: "eth", BaseUnitPrecision: 4, QuoteUnit: "twd", QuoteUnitPrecision: 1, MinBaseAmount: 0.01, MinQuoteAmount: 250},
+		{Id: "usdttwd", Name: "USDT/TWD", BaseUnit: "usdt", BaseUnitPrecision: 2, QuoteUnit: "twd", QuoteUnitPrecision: 3, MinBaseAmount: 8, MinQuoteAmount: 250},
+		{Id: "btcusdt", Name: "BTC/USDT", BaseUnit: "btc", BaseUnitPrecision: 6, QuoteUnit: "usdt", QuoteUnitPrecision: 2, MinBaseAmount: 0.0004, MinQuoteAmount: 8},
+	}
+
+	sampleCurrencies = []api.Currency{
+		{Id: "btc", Precision: 8},
+		{Id: "eth", Precision: 8},
+		{Id: "twd", Precision: 0},
+		{Id: "usdt", Precision: 6},
+	}
+
+	sampleMember = api.Member{
+		Sn:          "MAXTEST",
+		Name:        "maxtest",
+		Email:       "maxtest@example.com",
+		IsActivated: true,
+	}
+)
+
+func findRoute(method, path string) (route, bool) {
+	for _, rt := range routes {
+		if rt.method == method && matchPattern(rt.pattern, path) {
+			return rt, true
+		}
+	}
+	return route{}, false
+}
+
+func matchPattern(pattern, path string) bool {
+	pp := strings.Split(pattern, "/")
+	ps := strings.Split(path, "/")
+	if len(pp) != len(ps) {
+		return false
+	}
+
+	for i := range pp {
+		if strings.HasPrefix(pp[i], "{") {
+			if ps[i] == "" {
+				return false
+			}
+			continue
+		}
+		if pp[i] != ps[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func static(v interface{}) handler {
+	return func(*Server, *Request) (int, interface{}) {
+		return http.StatusOK, v
+	}
+}
+
+func notFound(record string) handler {
+	return func(*Server, *Request) (int, interface{}) {
+		return errorResponse(http.StatusNotFound, 0, record+" not found")
+	}
+}
+
+func errorResponse(status, code int, message string) (int, interface{}) {
+	return status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
+
+func (s *Server) ticker(req *Request) (int, interface{}) {
+	market := req.Path[strings.LastIndex(req.Path, "/")+1:]
+	for _, m := range sampleMarkets {
+		if m.Id == market {
+			return http.StatusOK, sampleTicker()
+		}
+	}
+
+	return errorResponse(http.StatusNotFound, 0, "market not found")
+}
+
+func (s *Server) tickers(req *Request) (int, interface{}) {
+	tickers := make(map[string]interface{}, len(sampleMarkets))
+	for _, m := range sampleMarkets {
+		tickers[m.Id] = sampleTicker()
+	}
+
+	return http.StatusOK, tickers
+}
+
+func sampleTicker() api.Ticker {
+	return api.Ticker{
+		At:   int32(time.Now().Unix()),
+		Buy:  "0.0",
+		Sell: "0.0",
+		Open: "0.0",
+		Low:  "0.0",
+		High: "0.0",
+		Last: "0.0",
+		Vol:  "0.0",
+	}
+}
+
+func (s *Server) depth(req *Request) (int, interface{}) {
+	return http.StatusOK, map[string]interface{}{
+		"timestamp": time.Now().Unix(),
+		"asks":      []interface{}{},
+		"bids":      []interface{}{},
+	}
+}
+
+func (s *Server) timestamp(req *Request) (int, interface{}) {
+	return http.StatusOK, time.Now().Unix()
+}
+
+// AddOrder stores an order in the server, as if it was created with CreateOrder().
+// The order id is assigned if missing, and returned.
+func (s *Server) AddOrder(o api.Order) int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if o.Id == 0 {
+		o.Id = s.lastOrderID + 1
+	}
+	if o.Id > s.lastOrderID {
+		s.lastOrderID = o.Id
+	}
+	if o.State == "" {
+		o.State = "wait"
+	}
+	if o.CreatedAt == 0 {
+		o.CreatedAt = int32(time.Now().Unix())
+	}
+	if o.RemainingVolume == "" {
+		o.RemainingVolume = o.Volume
+	}
+	if o.ExecutedVolume == "" {
+		o.ExecutedVolume = "0.0"
+	}
+
+	s.orders[o.Id] = &o
+	return o.Id
+}
+
+// Orders returns the orders stored in the server, sorted by id.
+func (s *Server) Orders() []api.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedOrders(func(*api.Order) bool { return true })
+}
+
+func (s *Server) sortedOrders(match func(*api.Order) bool) []api.Order {
+	orders := make([]api.Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		if match(o) {
+			orders = append(orders, *o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Id < orders[j].Id
+	})
+
+	return orders
+}
+
+func (s *Server) listOrders(req *Request) (int, interface{}) {
+	market := req.Param("market")
+	state := req.Param("state")
+	if state == "" {
+		state = "wait"
+	}
+
+	s.mu.Lock()
+	orders := s.sortedOrders(func(o *api.Order) bool {
+		return o.Market == market && o.State == state
+	})
+	s.mu.Unlock()
+
+	if req.Param("order_by") == "desc" {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+
+	return http.StatusOK, orders
+}
+
+// AddTrade stores a trade in the server. Trades with an order id are
+// trades of the account, listed by MyTrades(). The trade id is assigned
+// if missing, and returned.
+func (s *Server) AddTrade(t api.Trade) int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.Id == 0 {
+		t.Id = s.lastTradeID + 1
+	}
+	if t.Id > s.lastTradeID {
+		s.lastTradeID = t.Id
+	}
+	if t.CreatedAt == 0 {
+		t.CreatedAt = int32(time.Now().Unix())
+	}
+
+	s.trades = append(s.trades, t)
+	return t.Id
+}
+
+func (s *Server) listTrades(req *Request) (int, interface{}) {
+	trades := s.filterTrades(req, false)
+	for i := range trades {
+		trades[i].OrderId = 0
+		trades[i].Fee = ""
+		trades[i].FeeCurrency = ""
+	}
+	return http.StatusOK, trades
+}
+
+func (s *Server) listMyTrades(req *Request) (int, interface{}) {
+	return http.StatusOK, s.filterTrades(req, true)
+}
+
+// filterTrades returns the trades of the market between the from and to
+// trade ids, newest first unless order_by is asc.
+func (s *Server) filterTrades(req *Request, mine bool) []api.Trade {
+	market := req.Param("market")
+	from, _ := strconv.Atoi(req.Param("from"))
+	to, _ := strconv.Atoi(req.Param("to"))
+
+	s.mu.Lock()
+	trades := make([]api.Trade, 0, len(s.trades))
+	for _, t := range s.trades {
+		if t.Market != market || (mine && t.OrderId == 0) {
+			continue
+		}
+		if (from > 0 && int(t.Id) <= from) || (to > 0 && int(t.Id) >= to) {
+			continue
+		}
+		trades = append(trades, t)
+	}
+	s.mu.Unlock()
+
+	asc := req.Param("order_by") == "asc"
+	sort.Slice(trades, func(i, j int) bool {
+		if asc {
+			return trades[i].Id < trades[j].Id
+		}
+		return trades[i].Id > trades[j].Id
+	})
+
+	return trades
+}
+
+func (s *Server) createOrder(req *Request) (int, interface{}) {
+	o, status, err := newOrder(req.Param("market"), req.Params)
+	if err != nil {
+		return status, err
+	}
+
+	id := s.AddOrder(o)
+	return http.StatusOK, s.order(id)
+}
+
+func (s *Server) createOrders(req *Request) (int, interface{}) {
+	market := req.Param("market")
+	list, _ := req.Params["orders"].([]interface{})
+	if len(list) == 0 {
+		return errorResponse(http.StatusBadRequest, max.ErrorCodeCreateOrderFailed, "orders are required")
+	}
+
+	var orders []api.Order
+	for _, item := range list {
+		params, _ := item.(map[string]interface{})
+		o, status, err := newOrder(market, params)
+		if err != nil {
+			return status, err
+		}
+		orders = append(orders, o)
+	}
+
+	results := make([]api.Order, 0, len(orders))
+	for _, o := range orders {
+		results = append(results, s.order(s.AddOrder(o)))
+	}
+
+	return http.StatusOK, results
+}
+
+func newOrder(market string, params map[string]interface{}) (api.Order, int, interface{}) {
+	str := func(k string) string {
+		v, _ := params[k].(string)
+		return v
+	}
+
+	o := api.Order{
+		Market:    market,
+		Side:      str("side"),
+		OrdType:   str("ord_type"),
+		Price:     str("price"),
+		StopPrice: str("stop_price"),
+		Volume:    str("volume"),
+	}
+	if o.OrdType == "" {
+		o.OrdType = "limit"
+	}
+
+	var known bool
+	for _, m := range sampleMarkets {
+		known = known || m.Id == market
+	}
+
+	switch {
+	case !known:
+		status, body := errorResponse(http.StatusBadRequest, max.ErrorCodeCreateOrderFailed, "market not found")
+		return o, status, body
+	case o.Side != "buy" && o.Side != "sell":
+		status, body := errorResponse(http.StatusBadRequest, max.ErrorCodeCreateOrderFailed, "side must be buy or sell")
+		return o, status, body
+	case o.Volume == "":
+		status, body := errorResponse(http.StatusBadRequest, max.ErrorCodeCreateOrderFailed, "volume is required")
+		return o, status, body
+	}
+
+	return o, 0, nil
+}
+
+func (s *Server) order(id int32) api.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.orders[id]
+}
+
+func (s *Server) findOrder(req *Request) (*api.Order, bool) {
+	id, err := strconv.Atoi(req.Param("id"))
+	if err != nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[int32(id)]
+	return o, ok
+}
+
+func (s *Server) getOrder(req *Request) (int, interface{}) {
+	o, ok := s.findOrder(req)
+	if !ok {
+		return errorResponse(http.StatusNotFound, max.ErrorCodeOrderNotFound, "order not found")
+	}
+
+	return http.StatusOK, s.order(o.Id)
+}
+
+func (s *Server) cancelOrder(req *Request) (int, interface{}) {
+	o, ok := s.findOrder(req)
+	if !ok {
+		return errorResponse(http.StatusNotFound, max.ErrorCodeOrderNotFound, "order not found")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if o.State != "wait" {
+		return errorResponse(http.StatusBadRequest, max.ErrorCodeCancelOrderFailed, "order is not open")
+	}
+	o.State = "cancel"
+
+	return http.StatusOK, *o
+}
+
+func (s *Server) clearOrders(req *Request) (int, interface{}) {
+	market := req.Param("market")
+	side := req.Param("side")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := s.sortedOrders(func(o *api.Order) bool {
+		return o.State == "wait" &&
+			(market == "" || o.Market == market) &&
+			(side == "" || o.Side == side)
+	})
+	for i := range orders {
+		s.orders[orders[i].Id].State = "cancel"
+		orders[i].State = "cancel"
+	}
+
+	return http.StatusOK, orders
+}
+
+// paginate slices lists according to the page, limit and offset
+// parameters, and sets the Total header when pagination is requested.
+func paginate(w http.ResponseWriter, req *Request, v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return v
+	}
+
+	total := rv.Len()
+	limit, err := strconv.Atoi(req.Param("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	var start int
+	if req.Param("pagination") == "true" {
+		page, err := strconv.Atoi(req.Param("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		start = (page - 1) * limit
+		w.Header().Set(max.HeaderTotal, strconv.Itoa(total))
+	} else if offset, err := strconv.Atoi(req.Param("offset")); err == nil && offset > 0 {
+		start = offset
+	}
+
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return rv.Slice(start, end).Interface()
+}
@@ -0,0 +1,413 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maxtest provides fake MAX servers for testing code built on the
+// max package without reaching max-api.maicoin.com.
+//
+//	fake := maxtest.NewServer("access", "secret")
+//	defer fake.Close()
+//
+//	client := max.NewClient(max.BasePath(fake.URL), max.AuthToken("access", "secret"))
+package maxtest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	max "github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/api"
+)
+
+// NonceWindow is the maximum distance between a nonce and the server time.
+const NonceWindow = 30 * time.Second
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	// Params merges the query parameters and the signed JSON body,
+	// without the path and nonce of the payload.
+	Params map[string]interface{}
+	// Nonce of the signed payload, 0 for public requests
+	Nonce int64
+}
+
+// Param returns the parameter as a string, or "" if missing.
+func (r *Request) Param(key string) string {
+	switch v := r.Params[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// Fault is an error response injected with Fail().
+type Fault struct {
+	// HTTP status code, default to 500
+	Status int
+	// MAX error code
+	Code int
+	// MAX error message
+	Message string
+	// Times is the number of requests to fail, 0 fails all of them.
+	Times int
+}
+
+// Server is a fake MAX REST server implementing the /api/v2 routes of
+// docs/swagger-spec.json.
+//
+// Private routes verify the auth headers like the MAX servers do. Routes
+// answer their fixtures if any, otherwise orders and trades are kept in
+// memory and other routes return sample or empty data.
+type Server struct {
+	// URL of the server, to use with max.BasePath()
+	URL string
+
+	AccessKey string
+	SecretKey string
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	fixtures  map[string]interface{}
+	faults    map[string]*Fault
+	latencies map[string]time.Duration
+	requests  []*Request
+	// nonces used within the nonce window, older ones are rejected anyway
+	nonces map[int64]bool
+
+	orders      map[int32]*api.Order
+	lastOrderID int32
+	trades      []api.Trade
+	lastTradeID int32
+}
+
+// NewServer starts a fake server accepting the given credentials.
+func NewServer(accessKey, secretKey string) *Server {
+	s := &Server{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+	s.Reset()
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Reset removes the fixtures, faults, latencies, orders, trades and recorded
+// requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures = make(map[string]interface{})
+	s.faults = make(map[string]*Fault)
+	s.latencies = make(map[string]time.Duration)
+	s.requests = nil
+	s.nonces = make(map[int64]bool)
+	s.orders = make(map[int32]*api.Order)
+	s.lastOrderID = 0
+	s.trades = nil
+	s.lastTradeID = 0
+}
+
+// SetFixture sets the response body of a route, e.g. "GET", "/api/v2/markets".
+// The path is either the route of the swagger spec, like
+// "/api/v2/tickers/{market}", or the actual path, like "/api/v2/tickers/btctwd".
+//
+// body is encoded in JSON, unless it is a []byte or json.RawMessage.
+// Slices are paginated according to the page, limit and offset parameters.
+func (s *Server) SetFixture(method, path string, body interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[key(method, path)] = body
+}
+
+// Fail makes a route answer with an error. An empty method or path matches
+// every route.
+func (s *Server) Fail(method, path string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.faults[key(method, path)] = &f
+}
+
+// Delay makes a route wait before answering. An empty method or path matches
+// every route.
+func (s *Server) Delay(method, path string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies[key(method, path)] = d
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Request{}, s.requests...)
+}
+
+func key(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// keys returns the keys matching a request, from the most to the least
+// specific one. Fixtures only match the method and path.
+func keys(method, path, pattern string) []string {
+	return []string{
+		key(method, path), key(method, pattern),
+		key("", path), key("", pattern),
+		key(method, ""), key("", ""),
+	}
+}
+
+func (s *Server) fault(method, path, pattern string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys(method, path, pattern) {
+		f, ok := s.faults[k]
+		if !ok {
+			continue
+		}
+
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				delete(s.faults, k)
+			}
+		}
+		return f
+	}
+
+	return nil
+}
+
+func (s *Server) latency(method, path, pattern string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys(method, path, pattern) {
+		if d, ok := s.latencies[k]; ok {
+			return d
+		}
+	}
+	return 0
+}
+
+func (s *Server) fixture(method, path, pattern string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys(method, path, pattern)[:2] {
+		if v, ok := s.fixtures[k]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, ok := findRoute(r.Method, r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, 0, "route not found")
+		return
+	}
+
+	if d := s.latency(r.Method, r.URL.Path, rt.pattern); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	req := &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header,
+		Params: make(map[string]interface{}),
+	}
+	for k, v := range r.URL.Query() {
+		req.Params[k] = v[0]
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	if rt.private {
+		if status, code, msg := s.authenticate(r, body, req); code != 0 {
+			s.record(req)
+			writeError(w, status, code, msg)
+			return
+		}
+	} else if len(bytes.TrimSpace(body)) > 0 {
+		json.Unmarshal(body, &req.Params)
+	}
+	s.record(req)
+
+	if f := s.fault(r.Method, r.URL.Path, rt.pattern); f != nil {
+		writeError(w, f.Status, f.Code, f.Message)
+		return
+	}
+
+	if v, ok := s.fixture(r.Method, r.URL.Path, rt.pattern); ok {
+		writeJSON(w, req, http.StatusOK, v)
+		return
+	}
+
+	status, v := rt.handler(s, req)
+	writeJSON(w, req, status, v)
+}
+
+func (s *Server) record(req *Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+}
+
+// authenticate verifies the headers set by max.AuthToken(), and returns
+// the MAX error to answer if they are invalid.
+func (s *Server) authenticate(r *http.Request, body []byte, req *Request) (int, int, string) {
+	accessKey := r.Header.Get(max.HeaderAccessKey)
+	payload := r.Header.Get(max.HeaderPayloadKey)
+	signature := r.Header.Get(max.HeaderSignature)
+
+	if accessKey == "" || payload == "" || signature == "" {
+		return http.StatusUnauthorized, max.ErrorCodeAuthorizationFailed, "The access key, payload and signature headers are required."
+	}
+	if accessKey != s.AccessKey {
+		return http.StatusUnauthorized, max.ErrorCodeInvalidAccessKey, "The access key does not exist."
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.SecretKey))
+	mac.Write([]byte(payload))
+	if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return http.StatusUnauthorized, max.ErrorCodeIncorrectSignature, "The signature is incorrect."
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || !bytes.Equal(decoded, body) {
+		return http.StatusUnauthorized, max.ErrorCodeAuthorizationFailed, "The payload does not match the request body."
+	}
+
+	params := make(map[string]interface{})
+	d := json.NewDecoder(bytes.NewReader(decoded))
+	d.UseNumber()
+	if err := d.Decode(&params); err != nil {
+		return http.StatusUnauthorized, max.ErrorCodeAuthorizationFailed, "The payload is not valid JSON."
+	}
+
+	if path, _ := params["path"].(string); path != r.URL.Path {
+		return http.StatusUnauthorized, max.ErrorCodeAuthorizationFailed, "The payload path does not match the request path."
+	}
+
+	n, _ := params["nonce"].(json.Number)
+	nonce, err := n.Int64()
+	if err != nil {
+		return http.StatusUnauthorized, max.ErrorCodeInvalidNonce, "The nonce is missing."
+	}
+	if diff := time.Duration(nonce-time.Now().UnixNano()/int64(time.Millisecond)) * time.Millisecond; diff > NonceWindow || diff < -NonceWindow {
+		return http.StatusUnauthorized, max.ErrorCodeInvalidNonce, "The nonce is too far from the server time."
+	}
+
+	s.mu.Lock()
+	oldest := time.Now().Add(-NonceWindow).UnixNano() / int64(time.Millisecond)
+	for n := range s.nonces {
+		if n < oldest {
+			delete(s.nonces, n)
+		}
+	}
+	used := s.nonces[nonce]
+	s.nonces[nonce] = true
+	s.mu.Unlock()
+	if used {
+		return http.StatusUnauthorized, max.ErrorCodeNonceUsed, "The nonce has already been used."
+	}
+
+	delete(params, "path")
+	delete(params, "nonce")
+	for k, v := range params {
+		if n, ok := v.(json.Number); ok {
+			v = n.String()
+		}
+		req.Params[k] = v
+	}
+	req.Nonce = nonce
+
+	return 0, 0, ""
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	body := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeJSON(w http.ResponseWriter, req *Request, status int, v interface{}) {
+	var body []byte
+	switch b := v.(type) {
+	case []byte:
+		body = b
+	case json.RawMessage:
+		body = b
+	default:
+		v = paginate(w, req, v)
+
+		var err error
+		if body, err = json.Marshal(v); err != nil {
+			writeError(w, http.StatusInternalServerError, 0, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
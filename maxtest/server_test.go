@@ -0,0 +1,170 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maxtest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	max "github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/api"
+	"github.com/maicoin/max-exchange-api-go/maxtest"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestServerOrders(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	c := max.NewClient(max.BasePath(fake.URL), max.AuthToken("access", "secret"))
+	defer c.Close()
+
+	ctx := context.Background()
+	order, err := c.CreateOrder(ctx, "btctwd", "buy", types.MustParseDecimal("0.01"), max.Price(types.MustParseDecimal("100000")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 1 || order.State != types.OrderStateWait || order.Price.String() != "100000" {
+		t.Errorf("unexpected order %+v", order)
+	}
+
+	fake.AddOrder(api.Order{Market: "btctwd", Side: "sell", Price: "200000", Volume: "0.01"})
+	orders, err := c.Orders(ctx, "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].ID != order.ID {
+		t.Errorf("Orders = %v, want ascending ids", orders)
+	}
+
+	if _, err := c.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CancelOrder(ctx, 42); !max.IsOrderNotFound(err) {
+		t.Errorf("CancelOrder(42) = %v, want order not found", err)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 4 {
+		t.Fatalf("got %d requests, want 4", len(reqs))
+	}
+	if reqs[0].Param("volume") != "0.01" || reqs[0].Nonce == 0 {
+		t.Errorf("unexpected request %+v", reqs[0])
+	}
+}
+
+func TestServerTrades(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	for i := 1; i <= 5; i++ {
+		fake.AddTrade(api.Trade{Market: "btctwd", Price: "100000", Volume: "0.1", OrderId: int32(i % 2)})
+	}
+	fake.AddTrade(api.Trade{Market: "ethtwd", Price: "5000", Volume: "1"})
+
+	c := max.NewClient(max.BasePath(fake.URL), max.AuthToken("access", "secret"))
+	defer c.Close()
+
+	ctx := context.Background()
+	trades, err := c.Trades(ctx, "btctwd", max.From(1), max.To(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || trades[0].ID != 4 || trades[2].ID != 2 {
+		t.Errorf("Trades(From(1), To(5)) = %v, want 4 to 2", trades)
+	}
+
+	var ids []int32
+	it := c.MyTradesIter(ctx, "btctwd", max.Limit(1))
+	for it.Next() {
+		ids = append(ids, it.Trade().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != 5 || ids[1] != 3 || ids[2] != 1 {
+		t.Errorf("MyTradesIter() = %v, want 5, 3, 1", ids)
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	c := max.NewClient(max.BasePath(fake.URL), max.AuthToken("access", "wrong"))
+	defer c.Close()
+
+	_, err := c.Me(context.Background())
+	apiErr, ok := err.(*max.APIError)
+	if !ok || apiErr.Code != max.ErrorCodeIncorrectSignature {
+		t.Errorf("Me() = %v, want an incorrect signature error", err)
+	}
+}
+
+func TestServerConcurrentAuth(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	c := max.NewClient(max.BasePath(fake.URL), max.AuthToken("access", "secret"))
+	defer c.Close()
+
+	errs := make(chan error, 50)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := c.Me(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Me() = %v", err)
+		}
+	}
+}
+
+func TestServerFixturesAndFaults(t *testing.T) {
+	fake := maxtest.NewServer("access", "secret")
+	defer fake.Close()
+
+	c := max.NewClient(max.BasePath(fake.URL))
+	defer c.Close()
+
+	ctx := context.Background()
+	fake.SetFixture("GET", "/api/v2/markets", []api.Market{{Id: "maxtwd"}})
+	markets, err := c.Markets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markets) != 1 || markets[0].Id != "maxtwd" {
+		t.Errorf("Markets = %v", markets)
+	}
+
+	fake.Fail("GET", "/api/v2/tickers/{market}", maxtest.Fault{Status: http.StatusTooManyRequests, Times: 1})
+	if _, err := c.Ticker(ctx, "btctwd"); !max.IsRateLimited(err) {
+		t.Errorf("Ticker() = %v, want rate limited", err)
+	}
+	if _, err := c.Ticker(ctx, "btctwd"); err != nil {
+		t.Errorf("Ticker() = %v after the fault", err)
+	}
+
+	fake.Delay("", "", 100*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Currencies(ctx); err == nil {
+		t.Error("Currencies() succeeded despite the latency")
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maxtest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// Subscription is a channel subscribed on the WSServer.
+type Subscription struct {
+	Channel string
+	Market  string
+}
+
+// WSServer is a fake MAX websocket server.
+//
+// It sends a challenge to every connection, verifies the auth answer of
+// max.WSAuthToken(), and records the subscribe and unsubscribe commands.
+// Tests push events with the Send* methods: public events go to the
+// connections subscribed to their channel and market, private events to
+// the authenticated connections.
+//
+//	fake := maxtest.NewWSServer("access", "secret")
+//	defer fake.Close()
+//
+//	client, err := max.NewWSClient(max.WSURL(fake.URL), max.WSAuthToken("access", "secret"))
+type WSServer struct {
+	// URL of the server, to use with max.WSURL()
+	URL string
+
+	AccessKey string
+	SecretKey string
+
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	conns map[*wsConn]bool
+}
+
+type wsConn struct {
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	challenge string
+
+	// guarded by WSServer.mu
+	authenticated bool
+	subs          map[Subscription]bool
+}
+
+func (c *wsConn) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *wsConn) writeJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.write(websocket.TextMessage, b)
+}
+
+// NewWSServer starts a fake websocket server accepting the given credentials.
+func NewWSServer(accessKey, secretKey string) *WSServer {
+	s := &WSServer{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		conns:     make(map[*wsConn]bool),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveWS))
+	s.URL = "ws" + strings.TrimPrefix(s.srv.URL, "http")
+
+	return s
+}
+
+// Close disconnects the clients and shuts down the server.
+func (s *WSServer) Close() {
+	s.Disconnect()
+	s.srv.Close()
+}
+
+// Disconnect closes all the connections, e.g. to test reconnections.
+func (s *WSServer) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.conns {
+		c.conn.Close()
+	}
+}
+
+// Conns returns the number of open connections.
+func (s *WSServer) Conns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// Subscriptions returns the active subscriptions of all the connections,
+// sorted by channel and market.
+func (s *WSServer) Subscriptions() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := make(map[Subscription]bool)
+	for c := range s.conns {
+		for sub := range c.subs {
+			set[sub] = true
+		}
+	}
+
+	subs := make([]Subscription, 0, len(set))
+	for sub := range set {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].Channel != subs[j].Channel {
+			return subs[i].Channel < subs[j].Channel
+		}
+		return subs[i].Market < subs[j].Market
+	})
+
+	return subs
+}
+
+// WaitSubscribed waits until a connection subscribes the channel of the
+// market, and reports whether it did within the timeout.
+func (s *WSServer) WaitSubscribed(channel, market string, timeout time.Duration) bool {
+	return s.wait(timeout, func() bool {
+		for c := range s.conns {
+			if c.subs[Subscription{Channel: channel, Market: market}] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WaitAuthenticated waits until a connection is authenticated, and reports
+// whether it was within the timeout.
+func (s *WSServer) WaitAuthenticated(timeout time.Duration) bool {
+	return s.wait(timeout, func() bool {
+		for c := range s.conns {
+			if c.authenticated {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (s *WSServer) wait(timeout time.Duration, cond func() bool) bool {
+	timer := time.AfterFunc(timeout, func() {
+		s.mu.Lock()
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !cond() {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		s.cond.Wait()
+	}
+	return true
+}
+
+// Send sends a JSON message to all the connections.
+func (s *WSServer) Send(msg interface{}) {
+	for _, c := range s.filter(func(*wsConn) bool { return true }) {
+		c.writeJSON(msg)
+	}
+}
+
+// SendRaw sends a text frame to all the connections, e.g. malformed JSON.
+func (s *WSServer) SendRaw(data []byte) {
+	for _, c := range s.filter(func(*wsConn) bool { return true }) {
+		c.write(websocket.TextMessage, data)
+	}
+}
+
+// SendTicker sends a ticker event to the subscribers of its market.
+func (s *WSServer) SendTicker(ev *models.TickerEvent) {
+	s.publish("ticker", ev.Market, map[string]interface{}{
+		"at":   millis(ev.At),
+		"buy":  ev.Buy,
+		"sell": ev.Sell,
+		"open": ev.Open,
+		"last": ev.Last,
+		"high": ev.High,
+		"low":  ev.Low,
+		"vol":  ev.Volume,
+	})
+}
+
+// SendOrderBook sends an order book event to the subscribers of its market.
+func (s *WSServer) SendOrderBook(ev *models.OrderBookEvent) {
+	s.publish("orderbook", ev.Market, map[string]interface{}{
+		"action":   ev.Action,
+		"id":       ev.ID,
+		"side":     ev.Side,
+		"price":    ev.Price,
+		"volume":   ev.Volume,
+		"ord_type": ev.OrderType,
+	})
+}
+
+// SendTrade sends a trade event to the subscribers of its market.
+func (s *WSServer) SendTrade(ev *models.TradeEvent) {
+	s.publish("trade", ev.Market, map[string]interface{}{
+		"at":     millis(ev.At),
+		"price":  ev.Price,
+		"volume": ev.Volume,
+	})
+}
+
+// SendAccount sends balance updates to the authenticated connections.
+func (s *WSServer) SendAccount(events ...*models.AccountEvent) {
+	accounts := make([]map[string]interface{}, len(events))
+	for i, ev := range events {
+		accounts[i] = map[string]interface{}{
+			"currency": ev.Currency,
+			"balance":  ev.Balance,
+			"locked":   ev.Locked,
+			"at":       millis(ev.At),
+		}
+	}
+
+	msg := map[string]interface{}{"info": "account", "accounts": accounts}
+	for _, c := range s.filter(func(c *wsConn) bool { return c.authenticated }) {
+		c.writeJSON(msg)
+	}
+}
+
+func (s *WSServer) publish(channel, market string, msg map[string]interface{}) {
+	msg["info"] = channel
+	msg["market"] = market
+
+	sub := Subscription{Channel: channel, Market: market}
+	for _, c := range s.filter(func(c *wsConn) bool { return c.subs[sub] }) {
+		c.writeJSON(msg)
+	}
+}
+
+func (s *WSServer) filter(match func(*wsConn) bool) []*wsConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var conns []*wsConn
+	for c := range s.conns {
+		if match(c) {
+			conns = append(conns, c)
+		}
+	}
+	return conns
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func (s *WSServer) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	c := &wsConn{
+		conn:      conn,
+		challenge: hex.EncodeToString(b),
+		subs:      make(map[Subscription]bool),
+	}
+
+	s.mu.Lock()
+	s.conns[c] = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	defer func() {
+		conn.Close()
+
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}()
+
+	if err := c.writeJSON(map[string]interface{}{"info": "challenge", "msg": c.challenge}); err != nil {
+		return
+	}
+
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		cmd := struct {
+			Cmd       string `json:"cmd"`
+			AccessKey string `json:"access_key"`
+			Answer    string `json:"answer"`
+			Channel   string `json:"channel"`
+			Params    struct {
+				Market string `json:"market"`
+			} `json:"params"`
+		}{}
+		if err := json.Unmarshal(b, &cmd); err != nil {
+			c.writeJSON(map[string]interface{}{"info": "error", "msg": "invalid message"})
+			continue
+		}
+
+		sub := Subscription{Channel: cmd.Channel, Market: cmd.Params.Market}
+		switch cmd.Cmd {
+		case "auth":
+			if !s.verify(c, cmd.AccessKey, cmd.Answer) {
+				c.writeJSON(map[string]interface{}{"info": "error", "msg": "authentication failed"})
+				continue
+			}
+			s.update(func() { c.authenticated = true })
+			c.writeJSON(map[string]interface{}{"info": "authenticated"})
+		case "subscribe":
+			s.update(func() { c.subs[sub] = true })
+			c.writeJSON(map[string]interface{}{"info": "subscribed", "channel": sub.Channel, "market": sub.Market})
+		case "unsubscribe":
+			s.update(func() { delete(c.subs, sub) })
+			c.writeJSON(map[string]interface{}{"info": "unsubscribed", "channel": sub.Channel, "market": sub.Market})
+		default:
+			c.writeJSON(map[string]interface{}{"info": "error", "msg": "unknown command " + cmd.Cmd})
+		}
+	}
+}
+
+func (s *WSServer) update(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn()
+	s.cond.Broadcast()
+}
+
+// verify checks the answer to the challenge, the hex HMAC-SHA256 of the
+// access key and the challenge signed with the secret key.
+func (s *WSServer) verify(c *wsConn, accessKey, answer string) bool {
+	if accessKey != s.AccessKey {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.SecretKey))
+	mac.Write([]byte(accessKey + c.challenge))
+
+	return hmac.Equal([]byte(answer), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maxtest_test
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	max "github.com/maicoin/max-exchange-api-go"
+	"github.com/maicoin/max-exchange-api-go/maxtest"
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestWSServer(t *testing.T) {
+	fake := maxtest.NewWSServer("access", "secret")
+	defer fake.Close()
+
+	errs := make(chan error, 10)
+	c, err := max.NewWSClient(
+		max.WSURL(fake.URL),
+		max.WSAuthToken("access", "secret"),
+		max.WSLogging(log.New(ioutil.Discard, "", 0)),
+		max.WSReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+		max.WSOnError(func(err error) { errs <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tickers, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := c.SubscribeAccount(make(chan *models.AccountEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !fake.WaitAuthenticated(time.Second) || !fake.WaitSubscribed("ticker", "btctwd", time.Second) {
+		t.Fatal("client not authenticated and subscribed")
+	}
+
+	fake.SendTicker(&models.TickerEvent{Market: "ethtwd"})
+	fake.SendTicker(&models.TickerEvent{Market: "btctwd", Ticker: models.Ticker{Last: types.MustParseDecimal("100")}})
+	select {
+	case ev := <-tickers.Chan():
+		if ev.Market != "btctwd" || ev.Last.String() != "100" {
+			t.Errorf("ticker = %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for ticker")
+	}
+
+	fake.SendAccount(&models.AccountEvent{Currency: "btc", Balance: types.MustParseDecimal("1.5")})
+	select {
+	case ev := <-accounts.Chan():
+		if ev.Currency != "btc" || ev.Balance.String() != "1.5" {
+			t.Errorf("account = %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for account")
+	}
+
+	fake.SendRaw([]byte("{not json"))
+	select {
+	case err := <-errs:
+		if _, ok := err.(*max.DecodeError); !ok {
+			t.Errorf("error = %v, want *DecodeError", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for decode error")
+	}
+
+	// The client reconnects and subscribes again.
+	fake.Disconnect()
+	time.Sleep(20 * time.Millisecond)
+	if !fake.WaitSubscribed("ticker", "btctwd", time.Second) {
+		t.Fatal("client not subscribed again")
+	}
+
+	tickers.Close()
+	if !waitFor(func() bool { return len(fake.Subscriptions()) == 0 }) {
+		t.Errorf("Subscriptions() = %v after unsubscribe", fake.Subscriptions())
+	}
+}
+
+func TestWSServerAuth(t *testing.T) {
+	fake := maxtest.NewWSServer("access", "secret")
+	defer fake.Close()
+
+	c, err := max.NewWSClient(
+		max.WSURL(fake.URL),
+		max.WSAuthToken("access", "wrong"),
+		max.WSLogging(log.New(ioutil.Discard, "", 0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if fake.WaitAuthenticated(100 * time.Millisecond) {
+		t.Error("authenticated with a wrong secret key")
+	}
+}
+
+func waitFor(cond func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return false
+}
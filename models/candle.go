@@ -21,22 +21,23 @@ import (
 )
 
 type Candle struct {
-	Time   time.Time   `json:"timestamp,omitempty"`
-	Open   types.Price `json:"open,omitempty"`
-	High   types.Price `json:"high,omitempty"`
-	Low    types.Price `json:"low,omitempty"`
-	Close  types.Price `json:"close,omitempty"`
-	Volume types.Price `json:"volume,omitempty"`
+	Time   time.Time    `json:"timestamp,omitempty"`
+	Open   types.Price  `json:"open,omitempty"`
+	High   types.Price  `json:"high,omitempty"`
+	Low    types.Price  `json:"low,omitempty"`
+	Close  types.Price  `json:"close,omitempty"`
+	Volume types.Volume `json:"volume,omitempty"`
 }
 
+// Array returns the candle as [timestamp, open, high, low, close, volume] in float64.
 func (c *Candle) Array() []float64 {
 	result := make([]float64, 6)
 	result[0] = float64(c.Time.Unix())
-	result[1] = c.Open
-	result[2] = c.High
-	result[3] = c.Low
-	result[4] = c.Close
-	result[5] = c.Volume
+	result[1] = c.Open.Float64()
+	result[2] = c.High.Float64()
+	result[3] = c.Low.Float64()
+	result[4] = c.Close.Float64()
+	result[5] = c.Volume.Float64()
 
 	return result
 }
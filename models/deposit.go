@@ -13,3 +13,21 @@
 // limitations under the License.
 
 package models
+
+import (
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// get details of a specific deposit
+type Deposit struct {
+	Currency      string             `json:"currency,omitempty"`
+	Amount        types.Decimal      `json:"amount,omitempty"`
+	Fee           types.Decimal      `json:"fee,omitempty"`
+	TxID          string             `json:"txid,omitempty"`
+	CreatedAt     time.Time          `json:"created_at,omitempty"`
+	Confirmations int32              `json:"confirmations,omitempty"`
+	UpdatedAt     time.Time          `json:"updated_at,omitempty"`
+	State         types.DepositState `json:"state,omitempty"`
+}
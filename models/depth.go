@@ -16,6 +16,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/maicoin/max-exchange-api-go/types"
@@ -34,8 +35,24 @@ type Bargain struct {
 }
 
 func (b *Bargain) MarshalJSON() ([]byte, error) {
-	return json.Marshal([]float64{
+	return json.Marshal([]types.Decimal{
 		b.Price,
 		b.Volume,
 	})
 }
+
+func (b *Bargain) UnmarshalJSON(data []byte) error {
+	var pair []types.Decimal
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+
+	if len(pair) != 2 {
+		return fmt.Errorf("models: invalid bargain %s", data)
+	}
+
+	b.Price = pair[0]
+	b.Volume = pair[1]
+
+	return nil
+}
@@ -42,4 +42,40 @@ type TradeEvent struct {
 	Price  types.Price  `json:"price,omitempty"`
 }
 
-type AccountEvent map[string]interface{}
+// AccountEvent is a balance update of one of your accounts.
+type AccountEvent struct {
+	Currency string        `json:"currency,omitempty"`
+	Balance  types.Decimal `json:"balance,omitempty"`
+	Locked   types.Decimal `json:"locked,omitempty"`
+	At       time.Time     `json:"at,omitempty"`
+}
+
+// OrderUpdate is the kind of change of an OrderEvent.
+type OrderUpdate string
+
+// Order updates, see OrderEvent.
+const (
+	OrderUpdateNew         OrderUpdate = "new"
+	OrderUpdatePartialFill OrderUpdate = "partial_fill"
+	OrderUpdateDone        OrderUpdate = "done"
+	OrderUpdateCancel      OrderUpdate = "cancel"
+	// OrderUpdateTriggered means a stop order was converted into a new order.
+	OrderUpdateTriggered OrderUpdate = "triggered"
+)
+
+// OrderEvent is a state change of one of your orders.
+type OrderEvent struct {
+	Update OrderUpdate `json:"update,omitempty"`
+	At     time.Time   `json:"at,omitempty"`
+	Order
+}
+
+// MarketEvent is an event of a public channel tagged with its channel and
+// market. Only the field of the channel is set.
+type MarketEvent struct {
+	Channel   string          `json:"channel,omitempty"`
+	Market    string          `json:"market,omitempty"`
+	Ticker    *TickerEvent    `json:"ticker,omitempty"`
+	OrderBook *OrderBookEvent `json:"orderbook,omitempty"`
+	Trade     *TradeEvent     `json:"trade,omitempty"`
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/maicoin/max-exchange-api-go/api"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// get your profile and accounts infomation
+type Member struct {
+	Sn                   string          `json:"sn,omitempty"`
+	Name                 string          `json:"name,omitempty"`
+	Language             string          `json:"language,omitempty"`
+	PhoneSet             bool            `json:"phone_set,omitempty"`
+	CountryCode          string          `json:"country_code,omitempty"`
+	IdentityNumber       string          `json:"identity_number,omitempty"`
+	InvoiceCarrierID     string          `json:"invoice_carrier_id,omitempty"`
+	InvoiceCarrierType   string          `json:"invoice_carrier_type,omitempty"`
+	IsDeleted            bool            `json:"is_deleted,omitempty"`
+	IsFrozen             bool            `json:"is_frozen,omitempty"`
+	IsActivated          bool            `json:"is_activated,omitempty"`
+	ProfileVerified      bool            `json:"profile_verified,omitempty"`
+	KycApproved          bool            `json:"kyc_approved,omitempty"`
+	KycState             string          `json:"kyc_state,omitempty"`
+	PhoneNumber          string          `json:"phone_number,omitempty"`
+	UserAgreementChecked bool            `json:"user_agreement_checked,omitempty"`
+	UserAgreementVersion string          `json:"user_agreement_version,omitempty"`
+	Bank                 *api.Bank       `json:"bank,omitempty"`
+	Documents            *api.MemberDocs `json:"documents,omitempty"`
+	Email                string          `json:"email,omitempty"`
+	Accounts             []*Account      `json:"accounts,omitempty"`
+	// type_guest, type_coin_1, type_coin_2, type_fiat
+	MemberType string `json:"member_type,omitempty"`
+	Level      int32  `json:"level,omitempty"`
+}
+
+// Account is the balance of a currency, see Member.
+type Account struct {
+	Currency string        `json:"currency,omitempty"`
+	Balance  types.Decimal `json:"balance,omitempty"`
+	Locked   types.Decimal `json:"locked,omitempty"`
+}
@@ -16,12 +16,54 @@ package models
 
 import (
 	"encoding/json"
-	"fmt"
-	"reflect"
+	"time"
 
 	"github.com/maicoin/max-exchange-api-go/types"
 )
 
+// get a specific order.
+type Order struct {
+	ID              int32            `json:"id,omitempty"`
+	Side            types.OrderSide  `json:"side,omitempty"`
+	OrderType       types.OrderType  `json:"ord_type,omitempty"`
+	Price           types.Price      `json:"price,omitempty"`
+	StopPrice       types.Price      `json:"stop_price,omitempty"`
+	AvgPrice        types.Price      `json:"avg_price,omitempty"`
+	State           types.OrderState `json:"state,omitempty"`
+	Market          string           `json:"market,omitempty"`
+	CreatedAt       time.Time        `json:"created_at,omitempty"`
+	Volume          types.Volume     `json:"volume,omitempty"`
+	RemainingVolume types.Volume     `json:"remaining_volume,omitempty"`
+	ExecutedVolume  types.Volume     `json:"executed_volume,omitempty"`
+	TradesCount     int32            `json:"trades_count,omitempty"`
+}
+
+// IsOpen reports whether the order is waiting for fulfillment, including
+// the stop orders waiting to be converted.
+func (o *Order) IsOpen() bool {
+	return o.State == types.OrderStateWait || o.State == types.OrderStateConvert
+}
+
+// IsBuy reports whether the order is a buy order.
+func (o *Order) IsBuy() bool {
+	return o.Side == types.OrderSideBuy
+}
+
+// FilledRatio returns the executed volume over the order volume, from 0 to 1.
+func (o *Order) FilledRatio() float64 {
+	if o.Volume.IsZero() {
+		return 0
+	}
+
+	return o.ExecutedVolume.Div(o.Volume, 8).Float64()
+}
+
+// get the order book of a specified market
+type OrderBook struct {
+	Asks []*Order `json:"asks,omitempty"`
+	Bids []*Order `json:"bids,omitempty"`
+}
+
 type OrderRequest struct {
 	Side      types.OrderSide `json:"side,omitempty"`
 	Volume    types.Volume    `json:"volume,omitempty"`
@@ -33,20 +75,20 @@ type OrderRequest struct {
 func (o *OrderRequest) MarshalJSON() ([]byte, error) {
 	t := make(map[string]string)
 
-	if o.Side != reflect.Zero(reflect.TypeOf(o.Side)).Interface() {
-		t["side"] = o.Side
+	if o.Side != "" {
+		t["side"] = string(o.Side)
 	}
-	if o.Volume != reflect.Zero(reflect.TypeOf(o.Volume)).Interface() {
-		t["volume"] = fmt.Sprintf("%v", o.Volume)
+	if !o.Volume.IsZero() {
+		t["volume"] = o.Volume.String()
 	}
-	if o.Price != reflect.Zero(reflect.TypeOf(o.Price)).Interface() {
-		t["price"] = fmt.Sprintf("%v", o.Price)
+	if !o.Price.IsZero() {
+		t["price"] = o.Price.String()
 	}
-	if o.StopPrice != reflect.Zero(reflect.TypeOf(o.StopPrice)).Interface() {
-		t["stop_price"] = fmt.Sprintf("%v", o.StopPrice)
+	if !o.StopPrice.IsZero() {
+		t["stop_price"] = o.StopPrice.String()
 	}
-	if o.OrderType != reflect.Zero(reflect.TypeOf(o.OrderType)).Interface() {
-		t["ord_type"] = o.OrderType
+	if o.OrderType != "" {
+		t["ord_type"] = string(o.OrderType)
 	}
 
 	return json.Marshal(t)
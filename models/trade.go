@@ -0,0 +1,43 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// get recent trades on market, sorted in reverse creation order
+type Trade struct {
+	ID        int32         `json:"id,omitempty"`
+	Price     types.Price   `json:"price,omitempty"`
+	Volume    types.Volume  `json:"volume,omitempty"`
+	Funds     types.Decimal `json:"funds,omitempty"`
+	Market    string        `json:"market,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+	// 'bid' or 'ask', according to maker
+	Side    string `json:"side,omitempty"`
+	OrderID int32  `json:"order_id,omitempty"`
+
+	// only available for your trades
+	FeeAmount   types.Decimal `json:"fee,omitempty"`
+	FeeCurrency string        `json:"fee_currency,omitempty"`
+}
+
+// Fee returns the trading fee paid and its currency, only available for your trades.
+func (t *Trade) Fee() (types.Decimal, string) {
+	return t.FeeAmount, t.FeeCurrency
+}
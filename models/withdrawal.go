@@ -0,0 +1,33 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// get details of a specific withdraw
+type Withdrawal struct {
+	UUID      string                `json:"uuid,omitempty"`
+	Currency  string                `json:"currency,omitempty"`
+	Amount    types.Decimal         `json:"amount,omitempty"`
+	Fee       types.Decimal         `json:"fee,omitempty"`
+	TxID      string                `json:"txid,omitempty"`
+	CreatedAt time.Time             `json:"created_at,omitempty"`
+	UpdatedAt time.Time             `json:"updated_at,omitempty"`
+	State     types.WithdrawalState `json:"state,omitempty"`
+}
@@ -20,10 +20,4 @@ import (
 
 type Market = api.Market
 type Currency = api.Currency
-type Order = api.Order
-type OrderBook = api.OrderBook
-type Trade = api.Trade
-type Member = api.Member
-type Deposit = api.Deposit
 type PaymentAddress = api.PaymentAddress
-type Withdrawal = api.Withdrawal
@@ -28,7 +28,7 @@ func TestNonce(t *testing.T) {
 
 		go func() {
 			defer wg.Done()
-			n := nonce()
+			n := nonce(0)
 			key := fmt.Sprintf("%d", n)
 
 			if _, loaded := pivot.LoadOrStore(key, n); loaded {
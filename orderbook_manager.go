@@ -0,0 +1,493 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// Order book actions of models.OrderBookEvent.
+const (
+	OrderBookActionAdd    = "add"
+	OrderBookActionUpdate = "update"
+	OrderBookActionRemove = "remove"
+)
+
+const (
+	orderBookBufferSize  = 1024
+	orderBookRetryPeriod = time.Second
+	// orderBookSnapshotLimit is the number of orders per side of the
+	// snapshots, the most allowed by asks_limit and bids_limit.
+	orderBookSnapshotLimit int32 = 200
+)
+
+// OrderBookSubscriber is implemented by the websocket client.
+type OrderBookSubscriber interface {
+	SubscribeOrderBook(market string, ch chan *models.OrderBookEvent, opts ...SubscribeOption) (OrderBookSubscription, error)
+}
+
+// OrderBookChange is passed to the OnChange() handlers of an OrderBookManager.
+type OrderBookChange struct {
+	Market string
+	// Event is the applied event, nil when the book was reloaded.
+	Event *models.OrderBookEvent
+	// Resynced reports that the book was reloaded from a snapshot.
+	Resynced bool
+}
+
+// OrderBookGapError reports an event inconsistent with the book, which
+// makes the OrderBookManager reload a snapshot.
+type OrderBookGapError struct {
+	Market string
+	Event  *models.OrderBookEvent
+	Reason string
+}
+
+func (e *OrderBookGapError) Error() string {
+	return fmt.Sprintf("max: %s order book out of sync: %s", e.Market, e.Reason)
+}
+
+type bookOrder struct {
+	bid    bool
+	price  types.Price
+	volume types.Volume
+}
+
+// bookSide keeps the price levels of a side, from the best to the worst price.
+type bookSide struct {
+	bid    bool
+	levels []*models.Bargain
+	// bound is the worst price of a truncated snapshot, the orders from
+	// this price on may be missing from the book.
+	bound *types.Price
+}
+
+// outOfRange reports whether the orders of the price, zero if unknown, may
+// be missing from the snapshot.
+func (s *bookSide) outOfRange(price types.Price) bool {
+	return s.bound != nil && (price.IsZero() || !s.better(price, *s.bound))
+}
+
+func (s *bookSide) better(p1, p2 types.Price) bool {
+	if s.bid {
+		return p1.GreaterThan(p2)
+	}
+	return p1.LessThan(p2)
+}
+
+// search returns the index of the level of the price, or where to insert it.
+func (s *bookSide) search(price types.Price) int {
+	return sort.Search(len(s.levels), func(i int) bool {
+		return !s.better(s.levels[i].Price, price)
+	})
+}
+
+func (s *bookSide) add(price types.Price, volume types.Volume) {
+	i := s.search(price)
+	if i < len(s.levels) && s.levels[i].Price.Equal(price) {
+		s.levels[i].Volume = s.levels[i].Volume.Add(volume)
+	} else {
+		s.levels = append(s.levels, nil)
+		copy(s.levels[i+1:], s.levels[i:])
+		s.levels[i] = &models.Bargain{Price: price, Volume: volume}
+	}
+
+	if s.levels[i].Volume.Sign() <= 0 {
+		s.levels = append(s.levels[:i], s.levels[i+1:]...)
+	}
+}
+
+// OrderBookManager maintains the order book of a market, loaded from
+// OrderBook() and kept up to date with the orderbook websocket events.
+//
+// Events inconsistent with the book, like updating an unknown order or
+// crossing the best prices, make it reload the book. The snapshots are
+// limited to the best 200 orders of each side; the unknown orders
+// beyond them are added when updated, so the levels past the snapshot
+// depth may be incomplete.
+type OrderBookManager struct {
+	market string
+	rest   PublicAPI
+	sub    OrderBookSubscription
+
+	mu     sync.RWMutex
+	orders map[int]*bookOrder
+	bids   *bookSide
+	asks   *bookSide
+	synced bool
+
+	handlersMu sync.RWMutex
+	onChange   []func(OrderBookChange)
+	onError    []func(error)
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+// NewOrderBookManager subscribes the order book events of the market and
+// loads its snapshot. Call Close() to unsubscribe.
+func NewOrderBookManager(ctx context.Context, rest PublicAPI, ws OrderBookSubscriber, market string) (*OrderBookManager, error) {
+	m := &OrderBookManager{
+		market: market,
+		rest:   rest,
+		orders: make(map[int]*bookOrder),
+		bids:   &bookSide{bid: true},
+		asks:   &bookSide{},
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+
+	// Subscribe first so that no event is missed while loading the snapshot.
+	ch := make(chan *models.OrderBookEvent, orderBookBufferSize)
+	sub, err := ws.SubscribeOrderBook(market, ch)
+	if err != nil {
+		return nil, err
+	}
+	m.sub = sub
+
+	if err := m.load(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	// The events queued while loading the snapshot may be part of it
+	// already, they are applied leniently.
+	go m.run(sub.Chan(), len(ch))
+
+	return m, nil
+}
+
+// Close unsubscribes the order book events.
+func (m *OrderBookManager) Close() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		m.sub.Close()
+	})
+	<-m.doneCh
+}
+
+// OnChange registers a handler called after every change of the book.
+// Handlers are called sequentially and must not block.
+func (m *OrderBookManager) OnChange(h func(OrderBookChange)) {
+	m.handlersMu.Lock()
+	defer m.handlersMu.Unlock()
+
+	m.onChange = append(m.onChange, h)
+}
+
+// OnError registers a handler called with the gaps detected, see
+// OrderBookGapError, and the failures to reload the book.
+func (m *OrderBookManager) OnError(h func(error)) {
+	m.handlersMu.Lock()
+	defer m.handlersMu.Unlock()
+
+	m.onError = append(m.onError, h)
+}
+
+func (m *OrderBookManager) notify(change OrderBookChange) {
+	m.handlersMu.RLock()
+	defer m.handlersMu.RUnlock()
+
+	for _, h := range m.onChange {
+		h(change)
+	}
+}
+
+func (m *OrderBookManager) reportError(err error) {
+	m.handlersMu.RLock()
+	defer m.handlersMu.RUnlock()
+
+	for _, h := range m.onError {
+		h(err)
+	}
+}
+
+// load replaces the book with a snapshot of the REST API.
+func (m *OrderBookManager) load(ctx context.Context) error {
+	book, err := m.rest.OrderBook(ctx, m.market, AsksLimit(orderBookSnapshotLimit), BidsLimit(orderBookSnapshotLimit))
+	if err != nil {
+		return err
+	}
+
+	orders := make(map[int]*bookOrder)
+	bids := &bookSide{bid: true}
+	asks := &bookSide{}
+	for _, o := range append(append([]*models.Order{}, book.Bids...), book.Asks...) {
+		volume := o.RemainingVolume
+		if volume.IsZero() {
+			volume = o.Volume
+		}
+
+		bo := &bookOrder{bid: o.Side == types.OrderSideBuy, price: o.Price, volume: volume}
+		orders[int(o.ID)] = bo
+		if bo.bid {
+			bids.add(bo.price, bo.volume)
+		} else {
+			asks.add(bo.price, bo.volume)
+		}
+	}
+	// A full side may have been truncated, the orders beyond its worst
+	// price are unknown.
+	if n := len(book.Bids); n >= int(orderBookSnapshotLimit) {
+		bids.bound = &book.Bids[n-1].Price
+	}
+	if n := len(book.Asks); n >= int(orderBookSnapshotLimit) {
+		asks.bound = &book.Asks[n-1].Price
+	}
+
+	m.mu.Lock()
+	m.orders = orders
+	m.bids = bids
+	m.asks = asks
+	m.synced = true
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *OrderBookManager) run(events <-chan *models.OrderBookEvent, lenient int) {
+	defer close(m.doneCh)
+
+	for ev := range events {
+		var err error
+		if lenient > 0 {
+			lenient--
+			m.apply(ev, true)
+		} else {
+			err = m.apply(ev, false)
+		}
+
+		if err == nil {
+			m.notify(OrderBookChange{Market: m.market, Event: ev})
+			continue
+		}
+
+		m.reportError(err)
+		if !m.resync() {
+			return
+		}
+		lenient = len(events)
+		m.notify(OrderBookChange{Market: m.market, Resynced: true})
+	}
+}
+
+// resync reloads the book until it succeeds or the manager is closed.
+func (m *OrderBookManager) resync() bool {
+	m.mu.Lock()
+	m.synced = false
+	m.mu.Unlock()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), orderBookRetryPeriod*10)
+		err := m.load(ctx)
+		cancel()
+		if err == nil {
+			return true
+		}
+		m.reportError(err)
+
+		select {
+		case <-time.After(orderBookRetryPeriod):
+		case <-m.stopCh:
+			return false
+		}
+	}
+}
+
+// apply updates the book with an event. In lenient mode, inconsistent
+// events are applied as well as possible rather than reported.
+func (m *OrderBookManager) apply(ev *models.OrderBookEvent, lenient bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gap := func(reason string) error {
+		return &OrderBookGapError{Market: m.market, Event: ev, Reason: reason}
+	}
+
+	bid := ev.Side == types.OrderSideBuy || ev.Side == "bid"
+	o, known := m.orders[ev.ID]
+
+	switch ev.Action {
+	case OrderBookActionAdd, OrderBookActionUpdate:
+		if !known && ev.Action == OrderBookActionUpdate && !lenient && !m.side(bid).outOfRange(ev.Price) {
+			return gap(fmt.Sprintf("update of unknown order %d", ev.ID))
+		}
+		if known && ev.Action == OrderBookActionAdd && !lenient {
+			return gap(fmt.Sprintf("order %d added twice", ev.ID))
+		}
+		if known {
+			m.remove(ev.ID, o)
+		}
+
+		o = &bookOrder{bid: bid, price: ev.Price, volume: ev.Volume}
+		if o.volume.Sign() > 0 {
+			m.orders[ev.ID] = o
+			m.side(bid).add(o.price, o.volume)
+		}
+	case OrderBookActionRemove:
+		if !known {
+			if lenient || m.side(bid).outOfRange(ev.Price) {
+				return nil
+			}
+			return gap(fmt.Sprintf("removal of unknown order %d", ev.ID))
+		}
+		m.remove(ev.ID, o)
+	default:
+		return gap(fmt.Sprintf("unknown action %q", ev.Action))
+	}
+
+	if !lenient && len(m.bids.levels) > 0 && len(m.asks.levels) > 0 &&
+		!m.bids.levels[0].Price.LessThan(m.asks.levels[0].Price) {
+		return gap("crossed book")
+	}
+
+	return nil
+}
+
+func (m *OrderBookManager) side(bid bool) *bookSide {
+	if bid {
+		return m.bids
+	}
+	return m.asks
+}
+
+func (m *OrderBookManager) remove(id int, o *bookOrder) {
+	delete(m.orders, id)
+	m.side(o.bid).add(o.price, o.volume.Neg())
+}
+
+// Synced reports whether the book is consistent, it is false while reloading.
+func (m *OrderBookManager) Synced() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.synced
+}
+
+// BestBid returns the highest buy price level.
+func (m *OrderBookManager) BestBid() (models.Bargain, bool) {
+	return m.best(true)
+}
+
+// BestAsk returns the lowest sell price level.
+func (m *OrderBookManager) BestAsk() (models.Bargain, bool) {
+	return m.best(false)
+}
+
+func (m *OrderBookManager) best(bid bool) (models.Bargain, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	levels := m.side(bid).levels
+	if len(levels) == 0 {
+		return models.Bargain{}, false
+	}
+
+	return *levels[0], true
+}
+
+// Bids returns the n best buy price levels, from the highest price.
+// n <= 0 returns all of them.
+func (m *OrderBookManager) Bids(n int) []*models.Bargain {
+	return m.top(true, n)
+}
+
+// Asks returns the n best sell price levels, from the lowest price.
+// n <= 0 returns all of them.
+func (m *OrderBookManager) Asks(n int) []*models.Bargain {
+	return m.top(false, n)
+}
+
+func (m *OrderBookManager) top(bid bool, n int) []*models.Bargain {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	levels := m.side(bid).levels
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+
+	results := make([]*models.Bargain, n)
+	for i := range results {
+		l := *levels[i]
+		results[i] = &l
+	}
+
+	return results
+}
+
+// DepthTo returns the cumulative volume of a side from the best price
+// up to the given price included, side is `OrderSideBuy` or `OrderSideSell`.
+func (m *OrderBookManager) DepthTo(side types.OrderSide, price types.Price) types.Volume {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s := m.side(side == types.OrderSideBuy)
+
+	var total types.Volume
+	for _, l := range s.levels {
+		if s.better(price, l.Price) {
+			break
+		}
+		total = total.Add(l.Volume)
+	}
+
+	return total
+}
+
+// Mid returns the average of the best bid and ask prices, rounded to
+// one more digit than the prices.
+func (m *OrderBookManager) Mid() (types.Price, bool) {
+	bid, ask, ok := m.spread()
+	if !ok {
+		return types.Price{}, false
+	}
+
+	scale := bid.Scale()
+	if ask.Scale() > scale {
+		scale = ask.Scale()
+	}
+
+	return bid.Add(ask).Div(types.NewDecimalFromInt(2), scale+1), true
+}
+
+// Spread returns the difference between the best ask and bid prices.
+func (m *OrderBookManager) Spread() (types.Price, bool) {
+	bid, ask, ok := m.spread()
+	if !ok {
+		return types.Price{}, false
+	}
+
+	return ask.Sub(bid), true
+}
+
+func (m *OrderBookManager) spread() (bid, ask types.Price, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.bids.levels) == 0 || len(m.asks.levels) == 0 {
+		return bid, ask, false
+	}
+
+	return m.bids.levels[0].Price, m.asks.levels[0].Price, true
+}
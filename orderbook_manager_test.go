@@ -0,0 +1,179 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+type testOrderBookSubscriber struct {
+	ch chan *models.OrderBookEvent
+}
+
+func (s *testOrderBookSubscriber) SubscribeOrderBook(market string, ch chan *models.OrderBookEvent, opts ...SubscribeOption) (OrderBookSubscription, error) {
+	s.ch = ch
+	sub := newSubscriber(subscribeOptions{}, nil)
+	return &orderBookSubscription{
+		ch:  ch,
+		sub: sub,
+		unsubscribe: func() {
+			sub.close()
+			close(ch)
+		},
+	}, nil
+}
+
+func TestOrderBookManager(t *testing.T) {
+	var snapshots int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&snapshots, 1)
+		w.Write([]byte(`{
+			"bids":[{"id":1,"side":"buy","price":"99","volume":"2","remaining_volume":"1"},
+				{"id":2,"side":"buy","price":"98","volume":"3","remaining_volume":"3"}],
+			"asks":[{"id":3,"side":"sell","price":"101","volume":"1","remaining_volume":"1"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	sub := &testOrderBookSubscriber{}
+	m, err := NewOrderBookManager(context.Background(), c, sub, "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	changes := make(chan OrderBookChange, 10)
+	m.OnChange(func(change OrderBookChange) {
+		changes <- change
+	})
+	errs := make(chan error, 10)
+	m.OnError(func(err error) {
+		errs <- err
+	})
+
+	wait := func() OrderBookChange {
+		select {
+		case change := <-changes:
+			return change
+		case <-time.After(time.Second):
+			t.Fatal("no change notified")
+		}
+		return OrderBookChange{}
+	}
+
+	dec := types.MustParseDecimal
+	sub.ch <- &models.OrderBookEvent{Action: "add", ID: 4, Side: "buy", Price: dec("99"), Volume: dec("0.5")}
+	sub.ch <- &models.OrderBookEvent{Action: "add", ID: 5, Side: "sell", Price: dec("100"), Volume: dec("2")}
+	sub.ch <- &models.OrderBookEvent{Action: "update", ID: 3, Side: "sell", Price: dec("101"), Volume: dec("4")}
+	sub.ch <- &models.OrderBookEvent{Action: "remove", ID: 2, Side: "buy"}
+	for i := 0; i < 4; i++ {
+		wait()
+	}
+
+	if bid, ok := m.BestBid(); !ok || bid.Price.String() != "99" || bid.Volume.String() != "1.5" {
+		t.Errorf("BestBid() = %v %v, %v", bid.Price, bid.Volume, ok)
+	}
+	if asks := m.Asks(5); len(asks) != 2 || asks[0].Price.String() != "100" || asks[1].Volume.String() != "4" {
+		t.Errorf("Asks(5) = %v", asks)
+	}
+	if d := m.DepthTo(types.OrderSideSell, dec("101")); d.String() != "6" {
+		t.Errorf("DepthTo(sell, 101) = %s, want 6", d)
+	}
+	if p, _ := m.Mid(); p.String() != "99.5" {
+		t.Errorf("Mid() = %s, want 99.5", p)
+	}
+	if p, _ := m.Spread(); p.String() != "1" {
+		t.Errorf("Spread() = %s, want 1", p)
+	}
+
+	sub.ch <- &models.OrderBookEvent{Action: "update", ID: 42, Side: "buy", Price: dec("97"), Volume: dec("1")}
+	if change := wait(); !change.Resynced {
+		t.Errorf("change = %+v, want resynced", change)
+	}
+	if _, ok := (<-errs).(*OrderBookGapError); !ok {
+		t.Error("gap not reported")
+	}
+	if n := atomic.LoadInt32(&snapshots); n != 2 {
+		t.Errorf("%d snapshots loaded, want 2", n)
+	}
+	if bids := m.Bids(0); len(bids) != 2 || bids[0].Volume.String() != "1" {
+		t.Errorf("Bids(0) = %v", bids)
+	}
+}
+
+func TestOrderBookManagerTruncatedSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("asks_limit") != "200" || q.Get("bids_limit") != "200" {
+			t.Errorf("query %q, want limits of 200", r.URL.RawQuery)
+		}
+
+		book := map[string][]map[string]interface{}{}
+		for i := 0; i < 200; i++ {
+			book["asks"] = append(book["asks"], map[string]interface{}{
+				"id": i + 1, "side": "sell", "price": strconv.Itoa(101 + i), "volume": "1", "remaining_volume": "1",
+			})
+		}
+		book["bids"] = []map[string]interface{}{{"id": 201, "side": "buy", "price": "99", "volume": "1", "remaining_volume": "1"}}
+		json.NewEncoder(w).Encode(book)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	sub := &testOrderBookSubscriber{}
+	m, err := NewOrderBookManager(context.Background(), c, sub, "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	changes := make(chan OrderBookChange, 10)
+	m.OnChange(func(change OrderBookChange) {
+		changes <- change
+	})
+
+	dec := types.MustParseDecimal
+	// Orders beyond the truncated asks are unknown but consistent.
+	sub.ch <- &models.OrderBookEvent{Action: "update", ID: 2000, Side: "sell", Price: dec("400"), Volume: dec("2")}
+	sub.ch <- &models.OrderBookEvent{Action: "remove", ID: 2001, Side: "sell", Price: dec("500")}
+	// The bids are complete, an unknown order is a gap.
+	sub.ch <- &models.OrderBookEvent{Action: "update", ID: 2002, Side: "buy", Price: dec("98"), Volume: dec("1")}
+
+	var resynced []bool
+	for i := 0; i < 3; i++ {
+		select {
+		case change := <-changes:
+			resynced = append(resynced, change.Resynced)
+		case <-time.After(time.Second):
+			t.Fatal("no change notified")
+		}
+	}
+	if resynced[0] || resynced[1] || !resynced[2] {
+		t.Errorf("resynced = %v, want only the unknown bid to resync", resynced)
+	}
+}
@@ -0,0 +1,780 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// scale of the average prices of paper orders
+const paperAvgPriceScale = 8
+
+// PaperOption configures a PaperClient.
+type PaperOption func(*PaperClient)
+
+// PaperBalance sets the initial balance of a currency, e.g. "twd".
+func PaperBalance(currency string, amount types.Decimal) PaperOption {
+	return func(c *PaperClient) {
+		c.account(currency).balance = amount
+	}
+}
+
+// PaperFee sets the fee rate charged on the received funds of every trade,
+// default to 0.0015.
+func PaperFee(rate types.Decimal) PaperOption {
+	return func(c *PaperClient) {
+		c.fee = rate
+	}
+}
+
+// PaperPrecision sets what to do with prices and volumes having more
+// digits than the market allows, default to PrecisionReject.
+func PaperPrecision(policy PrecisionPolicy) PaperOption {
+	return func(c *PaperClient) {
+		c.validator.policy = policy
+	}
+}
+
+// PaperClient implements PrivateAPI with simulated balances and a local
+// matching engine, so that strategies can switch between live and paper
+// trading by swapping the client.
+//
+// Market and limit orders take the liquidity of Depth() when created, at
+// the prices of the levels they cross. The volume taken is not available
+// to the next orders until Depth() returns a snapshot of another time.
+// The remaining volume of a limit
+// order rests until public trades at or through its price fill it, up to
+// the volume of the trades. Stop orders are triggered by public trades;
+// triggered stop market orders fill at their stop price.
+//
+// Feed the public trades with Poll(), Run() or AddTrade(), e.g. from
+// SubscribeTrade(), but not both.
+//
+//	paper := max.NewPaperClient(client, max.PaperBalance("twd", types.MustParseDecimal("100000")))
+//	go paper.Run(ctx, 5*time.Second)
+//	order, err := paper.CreateOrder(ctx, "btctwd", max.OrderSideBuy, volume, max.Price(price))
+//
+// Deposits and withdrawals are not simulated, their methods return
+// nothing.
+type PaperClient struct {
+	api       PublicAPI
+	fee       types.Decimal
+	validator *orderValidator
+
+	mu       sync.Mutex
+	accounts map[string]*paperAccount
+	orders   []*paperOrder
+	byID     map[int32]*paperOrder
+	trades   []*models.Trade
+	// id of the last public trade fed by Poll(), per market
+	lastTradeIDs map[string]int32
+	// time of the first order of the markets without trades then, Poll()
+	// skips the trades before
+	pollSince   map[string]time.Time
+	depths      map[string]*paperDepth
+	nextOrderID int32
+	nextTradeID int32
+}
+
+// paperDepth is the volume taken from the levels of a Depth() snapshot.
+type paperDepth struct {
+	timestamp time.Time
+	taken     map[types.OrderSide][]*models.Bargain
+}
+
+type paperAccount struct {
+	balance types.Decimal
+	locked  types.Decimal
+}
+
+type paperOrder struct {
+	models.Order
+	market *models.Market
+	// funds of the fills, for the average price
+	funds types.Decimal
+	// funds still locked by the order, in the quote unit for buy orders
+	// and in the base unit for sell orders
+	locked    types.Decimal
+	triggered bool
+}
+
+// Interface check
+var _ PrivateAPI = &PaperClient{}
+
+// NewPaperClient returns a PaperClient using the market data of api.
+func NewPaperClient(api PublicAPI, opts ...PaperOption) *PaperClient {
+	c := &PaperClient{
+		api:          api,
+		fee:          types.MustParseDecimal("0.0015"),
+		validator:    newOrderValidator(PrecisionReject, func(ctx context.Context) ([]*models.Market, error) { return api.Markets(ctx) }),
+		accounts:     make(map[string]*paperAccount),
+		byID:         make(map[int32]*paperOrder),
+		lastTradeIDs: make(map[string]int32),
+		pollSince:    make(map[string]time.Time),
+		depths:       make(map[string]*paperDepth),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// account returns the account of a currency, must be called with c.mu held.
+func (c *PaperClient) account(currency string) *paperAccount {
+	a, ok := c.accounts[currency]
+	if !ok {
+		a = &paperAccount{}
+		c.accounts[currency] = a
+	}
+	return a
+}
+
+// paperError returns the error the server would respond with.
+func paperError(status, code int, path, msg string) error {
+	ej := errorJSON{}
+	ej.Error.Code = code
+	ej.Error.Message = msg
+	body, _ := json.Marshal(ej)
+
+	return &APIError{StatusCode: status, Code: code, Message: msg, Path: path, Body: body}
+}
+
+// Me returns the simulated accounts.
+func (c *PaperClient) Me(ctx context.Context, opts ...CallOption) (*models.Member, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	member := &models.Member{Name: "paper"}
+	for currency, a := range c.accounts {
+		member.Accounts = append(member.Accounts, &models.Account{
+			Currency: currency,
+			Balance:  a.balance,
+			Locked:   a.locked,
+		})
+	}
+	sort.Slice(member.Accounts, func(i, j int) bool {
+		return member.Accounts[i].Currency < member.Accounts[j].Currency
+	})
+
+	return member, nil
+}
+
+// Deposit returns an error, deposits are not simulated.
+func (c *PaperClient) Deposit(ctx context.Context, txid string, opts ...CallOption) (*models.Deposit, error) {
+	return nil, paperError(http.StatusNotFound, 0, "/api/v2/deposit", "deposit not found")
+}
+
+// Deposits returns no deposits.
+func (c *PaperClient) Deposits(ctx context.Context, opts ...CallOption) ([]*models.Deposit, error) {
+	return []*models.Deposit{}, nil
+}
+
+// Deprecated: Use DepositAddresses instead.
+//
+// DepositAddress returns no addresses.
+func (c *PaperClient) DepositAddress(ctx context.Context, opts ...CallOption) ([]*models.PaymentAddress, error) {
+	return []*models.PaymentAddress{}, nil
+}
+
+// DepositAddresses returns no addresses.
+func (c *PaperClient) DepositAddresses(ctx context.Context, opts ...CallOption) ([]*models.PaymentAddress, error) {
+	return []*models.PaymentAddress{}, nil
+}
+
+// CreateDepositAddresses creates no addresses.
+func (c *PaperClient) CreateDepositAddresses(ctx context.Context, currency string, opts ...CallOption) ([]*models.PaymentAddress, error) {
+	return []*models.PaymentAddress{}, nil
+}
+
+// Withdrawal returns an error, withdrawals are not simulated.
+func (c *PaperClient) Withdrawal(ctx context.Context, uuid string, opts ...CallOption) (*models.Withdrawal, error) {
+	return nil, paperError(http.StatusNotFound, 0, "/api/v2/withdrawal", "withdrawal not found")
+}
+
+// Withdrawals returns no withdrawals.
+func (c *PaperClient) Withdrawals(ctx context.Context, opts ...CallOption) ([]*models.Withdrawal, error) {
+	return []*models.Withdrawal{}, nil
+}
+
+// CreateOrder creates a simulated sell/buy order.
+//
+// Available `CallOption`:
+//
+//	Price(): price per unit
+//	StopPrice(): price per unit to trigger a stop order
+//	OrderType(): `OrderTypeLimit`, `OrderTypeMarket`, `OrderTypeStopLimit`, or `OrderTypeStopMarket`
+func (c *PaperClient) CreateOrder(ctx context.Context, market string, side types.OrderSide, volume types.Volume, opts ...CallOption) (*models.Order, error) {
+	ctx, o := callOptions(ctx, opts)
+
+	req := &models.OrderRequest{Side: side, Volume: volume}
+	var err error
+	if s, ok := o["price"].(string); ok {
+		if req.Price, err = types.ParsePrice(s); err != nil {
+			return nil, &ValidationError{Market: market, Field: "price", Value: s, Reason: err.Error()}
+		}
+	}
+	if s, ok := o["stop_price"].(string); ok {
+		if req.StopPrice, err = types.ParsePrice(s); err != nil {
+			return nil, &ValidationError{Market: market, Field: "stop_price", Value: s, Reason: err.Error()}
+		}
+	}
+	if t, ok := o["ord_type"].(string); ok {
+		req.OrderType = types.OrderType(t)
+	}
+
+	return c.createOrder(ctx, market, req)
+}
+
+// CreateOrders creates multiple simulated sell/buy orders, one after the
+// other. The orders before a rejected one stay open.
+//
+// Available `CallOption`:
+func (c *PaperClient) CreateOrders(ctx context.Context, market string, orderRequests []*models.OrderRequest, opts ...CallOption) ([]*models.Order, error) {
+	ctx, _ = callOptions(ctx, opts)
+
+	orders := make([]*models.Order, 0, len(orderRequests))
+	for _, req := range orderRequests {
+		order, err := c.createOrder(ctx, market, req)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (c *PaperClient) createOrder(ctx context.Context, market string, r *models.OrderRequest) (*models.Order, error) {
+	req, err := c.validator.validate(ctx, market, *r)
+	if err != nil {
+		return nil, err
+	}
+	if req.OrderType == "" {
+		req.OrderType = OrderTypeLimit
+	}
+	m, err := c.validator.market(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	// The public data is loaded without holding the lock.
+	var depth *models.Depth
+	if req.OrderType == OrderTypeMarket || req.OrderType == OrderTypeLimit {
+		if depth, err = c.api.Depth(ctx, market); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	_, known := c.lastTradeIDs[market]
+	c.mu.Unlock()
+	if !known {
+		trades, err := c.api.Trades(ctx, market, Limit(1))
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		if _, ok := c.lastTradeIDs[market]; !ok {
+			if len(trades) > 0 {
+				c.lastTradeIDs[market] = trades[0].ID
+			} else {
+				c.lastTradeIDs[market] = 0
+				c.pollSince[market] = time.Now().Truncate(time.Second)
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var levels []*models.Bargain
+	if depth != nil {
+		levels = c.available(market, depth, req.Side, matchingLevels(depth, req))
+	}
+
+	order := &paperOrder{
+		Order: models.Order{
+			Side:            req.Side,
+			OrderType:       req.OrderType,
+			Price:           req.Price,
+			StopPrice:       req.StopPrice,
+			State:           OrderStateWait,
+			Market:          market,
+			CreatedAt:       time.Now(),
+			Volume:          req.Volume,
+			RemainingVolume: req.Volume,
+		},
+		market: m,
+	}
+
+	// Lock the funds of the order.
+	lockCurrency, lock := m.BaseUnit, req.Volume
+	if req.Side == OrderSideBuy {
+		lockCurrency = m.QuoteUnit
+		switch req.OrderType {
+		case OrderTypeMarket:
+			lock = marketCost(levels, req.Volume)
+		case OrderTypeStopMarket:
+			lock = req.StopPrice.Mul(req.Volume)
+		default:
+			lock = req.Price.Mul(req.Volume)
+		}
+	}
+	a := c.account(lockCurrency)
+	if a.balance.LessThan(lock) {
+		return nil, paperError(http.StatusUnprocessableEntity, ErrorCodeCreateOrderFailed, "/api/v2/orders", "insufficient balance")
+	}
+	a.balance = a.balance.Sub(lock)
+	a.locked = a.locked.Add(lock)
+	order.locked = lock
+
+	c.nextOrderID++
+	order.ID = c.nextOrderID
+	c.orders = append(c.orders, order)
+	c.byID[order.ID] = order
+
+	// Take the liquidity of the order book.
+	now := order.CreatedAt
+	for _, l := range levels {
+		if order.RemainingVolume.IsZero() {
+			break
+		}
+		v := minDecimal(l.Volume, order.RemainingVolume)
+		c.fill(order, l.Price, v, now)
+		c.take(market, req.Side, l.Price, v)
+	}
+
+	// Market orders do not rest in the order book.
+	if req.OrderType == OrderTypeMarket && order.IsOpen() {
+		c.finish(order, OrderStateCancel)
+	}
+
+	copied := order.Order
+	return &copied, nil
+}
+
+// matchingLevels returns the depth levels crossed by an order, best first.
+func matchingLevels(depth *models.Depth, req models.OrderRequest) []*models.Bargain {
+	levels := append([]*models.Bargain{}, depth.Asks...)
+	better := func(a, b types.Price) bool { return a.LessThan(b) }
+	if req.Side == OrderSideSell {
+		levels = append([]*models.Bargain{}, depth.Bids...)
+		better = func(a, b types.Price) bool { return a.GreaterThan(b) }
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		return better(levels[i].Price, levels[j].Price)
+	})
+
+	if req.OrderType == OrderTypeMarket {
+		return levels
+	}
+
+	var crossed []*models.Bargain
+	for _, l := range levels {
+		if better(req.Price, l.Price) {
+			break
+		}
+		crossed = append(crossed, l)
+	}
+	return crossed
+}
+
+// available returns the levels without the volume taken by the previous
+// orders from the same snapshot, must be called with c.mu held.
+func (c *PaperClient) available(market string, depth *models.Depth, side types.OrderSide, levels []*models.Bargain) []*models.Bargain {
+	used, ok := c.depths[market]
+	if !ok || !used.timestamp.Equal(depth.Timestamp) {
+		used = &paperDepth{timestamp: depth.Timestamp, taken: make(map[types.OrderSide][]*models.Bargain)}
+		c.depths[market] = used
+	}
+
+	result := make([]*models.Bargain, 0, len(levels))
+	for _, l := range levels {
+		volume := l.Volume
+		for _, t := range used.taken[side] {
+			if t.Price.Equal(l.Price) {
+				volume = volume.Sub(t.Volume)
+			}
+		}
+		if volume.Sign() > 0 {
+			result = append(result, &models.Bargain{Price: l.Price, Volume: volume})
+		}
+	}
+	return result
+}
+
+// take records volume taken from a level of the last snapshot, must be
+// called with c.mu held.
+func (c *PaperClient) take(market string, side types.OrderSide, price types.Price, volume types.Volume) {
+	used := c.depths[market]
+	for _, t := range used.taken[side] {
+		if t.Price.Equal(price) {
+			t.Volume = t.Volume.Add(volume)
+			return
+		}
+	}
+	used.taken[side] = append(used.taken[side], &models.Bargain{Price: price, Volume: volume})
+}
+
+// marketCost returns the cost of buying volume from levels.
+func marketCost(levels []*models.Bargain, volume types.Volume) types.Decimal {
+	var cost types.Decimal
+	for _, l := range levels {
+		if volume.IsZero() {
+			break
+		}
+		v := minDecimal(l.Volume, volume)
+		cost = cost.Add(l.Price.Mul(v))
+		volume = volume.Sub(v)
+	}
+	return cost
+}
+
+func minDecimal(a, b types.Decimal) types.Decimal {
+	if a.LessThan(b) {
+		return a
+	}
+	return b
+}
+
+// fill executes volume of an order at price, must be called with c.mu held.
+func (c *PaperClient) fill(o *paperOrder, price types.Price, volume types.Volume, at time.Time) {
+	m := o.market
+	funds := price.Mul(volume)
+
+	trade := &models.Trade{
+		Price:     price,
+		Volume:    volume,
+		Funds:     funds,
+		Market:    o.Market,
+		CreatedAt: at,
+		Side:      "bid",
+		OrderID:   o.ID,
+	}
+
+	if o.Side == OrderSideBuy {
+		c.unlock(m.QuoteUnit, o, funds)
+		trade.FeeAmount, trade.FeeCurrency = volume.Mul(c.fee), m.BaseUnit
+		base := c.account(m.BaseUnit)
+		base.balance = base.balance.Add(volume.Sub(trade.FeeAmount))
+	} else {
+		c.unlock(m.BaseUnit, o, volume)
+		trade.Side = "ask"
+		trade.FeeAmount, trade.FeeCurrency = funds.Mul(c.fee), m.QuoteUnit
+		quote := c.account(m.QuoteUnit)
+		quote.balance = quote.balance.Add(funds.Sub(trade.FeeAmount))
+	}
+
+	c.nextTradeID++
+	trade.ID = c.nextTradeID
+	c.trades = append(c.trades, trade)
+
+	o.RemainingVolume = o.RemainingVolume.Sub(volume)
+	o.ExecutedVolume = o.ExecutedVolume.Add(volume)
+	o.funds = o.funds.Add(funds)
+	o.AvgPrice = o.funds.Div(o.ExecutedVolume, paperAvgPriceScale)
+	o.TradesCount++
+
+	if o.RemainingVolume.IsZero() {
+		c.finish(o, OrderStateDone)
+	}
+}
+
+// unlock spends amount of the funds locked by an order, must be called
+// with c.mu held.
+func (c *PaperClient) unlock(currency string, o *paperOrder, amount types.Decimal) {
+	a := c.account(currency)
+	a.locked = a.locked.Sub(amount)
+	o.locked = o.locked.Sub(amount)
+}
+
+// finish closes an order and releases its remaining locked funds, must be
+// called with c.mu held.
+func (c *PaperClient) finish(o *paperOrder, state types.OrderState) {
+	currency := o.market.BaseUnit
+	if o.Side == OrderSideBuy {
+		currency = o.market.QuoteUnit
+	}
+
+	a := c.account(currency)
+	a.locked = a.locked.Sub(o.locked)
+	a.balance = a.balance.Add(o.locked)
+	o.locked = types.Decimal{}
+	o.State = state
+}
+
+// AddTrade matches a public trade against the open orders of its market.
+func (c *PaperClient) AddTrade(trade *models.TradeEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.match(trade)
+}
+
+// match must be called with c.mu held.
+func (c *PaperClient) match(trade *models.TradeEvent) {
+	var bids, asks []*paperOrder
+	for _, o := range c.orders {
+		if o.Market != trade.Market || !o.IsOpen() {
+			continue
+		}
+
+		if !o.StopPrice.IsZero() && !o.triggered {
+			if (o.IsBuy() && trade.Price.LessThan(o.StopPrice)) || (!o.IsBuy() && trade.Price.GreaterThan(o.StopPrice)) {
+				continue
+			}
+			o.triggered = true
+			if o.OrderType == OrderTypeStopMarket {
+				c.fill(o, o.StopPrice, o.RemainingVolume, trade.At)
+				continue
+			}
+		}
+
+		if o.IsBuy() && !trade.Price.GreaterThan(o.Price) {
+			bids = append(bids, o)
+		}
+		if !o.IsBuy() && !trade.Price.LessThan(o.Price) {
+			asks = append(asks, o)
+		}
+	}
+
+	// Price then time priority, up to the volume of the trade.
+	sort.SliceStable(bids, func(i, j int) bool { return bids[i].Price.GreaterThan(bids[j].Price) })
+	sort.SliceStable(asks, func(i, j int) bool { return asks[i].Price.LessThan(asks[j].Price) })
+
+	for _, orders := range [][]*paperOrder{bids, asks} {
+		volume := trade.Volume
+		for _, o := range orders {
+			if volume.IsZero() {
+				break
+			}
+			v := minDecimal(volume, o.RemainingVolume)
+			c.fill(o, o.Price, v, trade.At)
+			volume = volume.Sub(v)
+		}
+	}
+}
+
+// Poll feeds the public trades executed since the last call with Trades(),
+// for the markets having open orders.
+func (c *PaperClient) Poll(ctx context.Context) error {
+	c.mu.Lock()
+	last := make(map[string]int32)
+	for _, o := range c.orders {
+		if o.IsOpen() {
+			last[o.Market] = c.lastTradeIDs[o.Market]
+		}
+	}
+	c.mu.Unlock()
+
+	for market, from := range last {
+		opts := []CallOption{From(from), OrderAsc(), Limit(1000)}
+		if from == 0 {
+			// The market had no trade, the newest ones are filtered by time
+			// rather than replaying the history from the first trade.
+			opts = []CallOption{Limit(1000)}
+		}
+		trades, err := c.api.Trades(ctx, market, opts...)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(trades, func(i, j int) bool {
+			return trades[i].ID < trades[j].ID
+		})
+
+		c.mu.Lock()
+		since := c.pollSince[market]
+		for _, t := range trades {
+			if t.ID <= c.lastTradeIDs[market] || t.CreatedAt.Before(since) {
+				continue
+			}
+			c.lastTradeIDs[market] = t.ID
+			c.match(&models.TradeEvent{At: t.CreatedAt, Market: market, Price: t.Price, Volume: t.Volume})
+		}
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Run calls Poll() every period until ctx is done or Poll() fails.
+func (c *PaperClient) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Poll(ctx); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CancelOrder cancels a simulated order.
+//
+// Available `CallOption`:
+func (c *PaperClient) CancelOrder(ctx context.Context, id int32, opts ...CallOption) (*models.Order, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, ok := c.byID[id]
+	if !ok {
+		return nil, paperError(http.StatusNotFound, ErrorCodeOrderNotFound, "/api/v2/order/delete", "order not found")
+	}
+	if !o.IsOpen() {
+		return nil, paperError(http.StatusUnprocessableEntity, ErrorCodeCancelOrderFailed, "/api/v2/order/delete", "order is not open")
+	}
+
+	c.finish(o, OrderStateCancel)
+
+	copied := o.Order
+	return &copied, nil
+}
+
+// CancelOrders cancels a series of simulated orders.
+//
+// Available `CallOption`:
+//
+//	OrderSide(): set tp cancel only sell (asks) or buy (bids) orders
+//	Market(): specify market like btctwd / ethbtc
+func (c *PaperClient) CancelOrders(ctx context.Context, opts ...CallOption) ([]*models.Order, error) {
+	_, o := callOptions(ctx, opts)
+	side, _ := o["side"].(string)
+	market, _ := o["market"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	orders := []*models.Order{}
+	for _, po := range c.orders {
+		if !po.IsOpen() || (side != "" && po.Side != types.OrderSide(side)) || (market != "" && po.Market != market) {
+			continue
+		}
+		c.finish(po, OrderStateCancel)
+
+		copied := po.Order
+		orders = append(orders, &copied)
+	}
+
+	return orders, nil
+}
+
+// Order returns details of a simulated order.
+//
+// Available `CallOption`:
+func (c *PaperClient) Order(ctx context.Context, id int32, opts ...CallOption) (*models.Order, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, ok := c.byID[id]
+	if !ok {
+		return nil, paperError(http.StatusNotFound, ErrorCodeOrderNotFound, "/api/v2/order", "order not found")
+	}
+
+	copied := o.Order
+	return &copied, nil
+}
+
+// Orders returns the simulated orders of a market.
+//
+// Available `CallOption`:
+//
+//	OrderState(): filter by state, default to 'OrderStateWait'
+//	OrderDesc(): use descending order by created time
+//	OrderAsc(): use ascending order by created time, default value
+//	Limit(): returned limit (1~1000, default 100)
+func (c *PaperClient) Orders(ctx context.Context, market string, opts ...CallOption) ([]*models.Order, error) {
+	_, o := callOptions(ctx, opts)
+
+	state := OrderStateWait
+	if s, ok := o["state"].(string); ok {
+		state = types.OrderState(s)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	desc, limit := listOptions(o, false, 100)
+	orders := []*models.Order{}
+	for i := range c.orders {
+		po := c.orders[i]
+		if desc {
+			po = c.orders[len(c.orders)-1-i]
+		}
+		if len(orders) < limit && po.Market == market && po.State == state {
+			copied := po.Order
+			orders = append(orders, &copied)
+		}
+	}
+
+	return orders, nil
+}
+
+// MyTrades returns the simulated trades which are sorted in reverse
+// creation order.
+//
+// Available `CallOption`:
+//
+//	OrderDesc(): use descending order by created time, default value
+//	OrderAsc(): use ascending order by created time
+//	Limit(): returned limit (1~1000, default 50)
+func (c *PaperClient) MyTrades(ctx context.Context, market string, opts ...CallOption) ([]*models.Trade, error) {
+	_, o := callOptions(ctx, opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	desc, limit := listOptions(o, true, 50)
+	trades := []*models.Trade{}
+	for i := range c.trades {
+		t := c.trades[i]
+		if desc {
+			t = c.trades[len(c.trades)-1-i]
+		}
+		if len(trades) < limit && t.Market == market {
+			copied := *t
+			trades = append(trades, &copied)
+		}
+	}
+
+	return trades, nil
+}
+
+// listOptions returns the order_by and limit options of a list sorted by
+// creation time.
+func listOptions(o Options, desc bool, limit int32) (bool, int) {
+	if by, ok := o["order_by"].(string); ok {
+		desc = by == orderDescending
+	}
+	if l, ok := o["limit"].(int32); ok {
+		limit = l
+	}
+	return desc, int(limit)
+}
@@ -0,0 +1,272 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func TestPaperClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(`[{"id":"btctwd","base_unit":"btc","base_unit_precision":8,"quote_unit":"twd","quote_unit_precision":1,"min_base_amount":0.0001,"min_quote_amount":10}]`))
+		case "/api/v2/depth":
+			w.Write([]byte(`{"timestamp":1530000000,"asks":[["102","1"],["101","0.5"]],"bids":[["99","1"],["98","2"]]}`))
+		case "/api/v2/trades":
+			if r.URL.Query().Get("from") == "" {
+				w.Write([]byte(`[{"id":10,"price":"100","volume":"1","market":"btctwd","created_at":1530000000}]`))
+			} else if r.URL.Query().Get("from") == "10" {
+				w.Write([]byte(`[{"id":11,"price":"100","volume":"1.5","market":"btctwd","created_at":1530000010}]`))
+			} else {
+				w.Write([]byte(`[]`))
+			}
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	d := types.MustParseDecimal
+	paper := NewPaperClient(c,
+		PaperBalance("twd", d("10000")),
+		PaperBalance("btc", d("1")),
+		PaperFee(d("0.001")),
+	)
+	ctx := context.Background()
+
+	// Takes 0.5 at 101 and 0.5 at 102.
+	order, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("1"), OrderType(OrderTypeMarket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.State != OrderStateDone || !order.AvgPrice.Equal(d("101.5")) || order.TradesCount != 2 {
+		t.Errorf("market order = %+v", order)
+	}
+
+	// Rests below the asks, locking 200 twd.
+	bid, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("2"), Price(d("100")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bid.IsOpen() || !bid.ExecutedVolume.IsZero() {
+		t.Errorf("limit order = %+v", bid)
+	}
+
+	// Crosses the best bid at 99.
+	ask, err := paper.CreateOrder(ctx, "btctwd", OrderSideSell, d("0.5"), Price(d("98.5")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ask.State != OrderStateDone || !ask.AvgPrice.Equal(d("99")) {
+		t.Errorf("crossing order = %+v", ask)
+	}
+
+	if _, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("1000"), Price(d("100"))); !IsInsufficientBalance(err) {
+		t.Errorf("err = %v, want insufficient balance", err)
+	}
+	if _, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("1"), Price(d("100.01"))); !IsValidationError(err) {
+		t.Errorf("err = %v, want validation error", err)
+	}
+
+	// The public trade 11 fills 1.5 of the bid.
+	if err := paper.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	bid, err = paper.Order(ctx, bid.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bid.RemainingVolume.Equal(d("0.5")) || !bid.IsOpen() {
+		t.Errorf("filled limit order = %+v", bid)
+	}
+	if err := paper.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, err := paper.CancelOrders(ctx, Market("btctwd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(canceled) != 1 || canceled[0].ID != bid.ID || canceled[0].State != OrderStateCancel {
+		t.Errorf("canceled = %+v", canceled)
+	}
+	if _, err := paper.CancelOrder(ctx, 100); !IsOrderNotFound(err) {
+		t.Errorf("err = %v, want order not found", err)
+	}
+
+	me, err := paper.Me(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]string{
+		// 10000 - 101.5 + 49.5 - 0.0495 - 150
+		"twd": {"9797.9505", "0"},
+		// 1 + 0.999 - 0.5 + 1.4985
+		"btc": {"2.9975", "0"},
+	}
+	for _, a := range me.Accounts {
+		w := want[a.Currency]
+		if !a.Balance.Equal(d(w[0])) || !a.Locked.Equal(d(w[1])) {
+			t.Errorf("%s balance %s locked %s, want %s %s", a.Currency, a.Balance, a.Locked, w[0], w[1])
+		}
+	}
+
+	trades, err := paper.MyTrades(ctx, "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 4 || trades[0].OrderID != bid.ID || !trades[0].FeeAmount.Equal(d("0.0015")) {
+		t.Errorf("trades = %+v", trades)
+	}
+
+	orders, err := paper.Orders(ctx, "btctwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("%d open orders, want none", len(orders))
+	}
+
+	orders, err = paper.Orders(ctx, "btctwd", OrderState(OrderStateDone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) == 0 {
+		t.Error("no done orders")
+	}
+	for _, o := range orders {
+		if o.State != OrderStateDone {
+			t.Errorf("order %d is %s, want done", o.ID, o.State)
+		}
+	}
+}
+
+func TestPaperClientStopOrders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(`[{"id":"btctwd","base_unit":"btc","base_unit_precision":8,"quote_unit":"twd","quote_unit_precision":1}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	d := types.MustParseDecimal
+	paper := NewPaperClient(c, PaperBalance("btc", d("1")), PaperFee(d("0")))
+	ctx := context.Background()
+
+	stop, err := paper.CreateOrder(ctx, "btctwd", OrderSideSell, d("1"), StopPrice(d("95")), OrderType(OrderTypeStopMarket))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paper.AddTrade(&models.TradeEvent{Market: "btctwd", Price: d("96"), Volume: d("1")})
+	if o, _ := paper.Order(ctx, stop.ID); !o.IsOpen() {
+		t.Errorf("stop order triggered above its price: %+v", o)
+	}
+
+	paper.AddTrade(&models.TradeEvent{Market: "btctwd", Price: d("94"), Volume: d("0.1")})
+	if o, _ := paper.Order(ctx, stop.ID); o.State != OrderStateDone || !o.AvgPrice.Equal(d("95")) {
+		t.Errorf("triggered stop order = %+v", o)
+	}
+
+	me, _ := paper.Me(ctx)
+	for _, a := range me.Accounts {
+		if a.Currency == "twd" && !a.Balance.Equal(d("95")) {
+			t.Errorf("twd balance %s, want 95", a.Balance)
+		}
+	}
+}
+
+func TestPaperClientDepthAndPoll(t *testing.T) {
+	var polls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(`[{"id":"btctwd","base_unit":"btc","base_unit_precision":8,"quote_unit":"twd","quote_unit_precision":1}]`))
+		case "/api/v2/depth":
+			w.Write([]byte(`{"timestamp":1530000000,"asks":[["102","1"],["101","0.5"]],"bids":[]}`))
+		case "/api/v2/trades":
+			// No trade when the orders are created, then an old and a new one.
+			if r.URL.Query().Get("limit") == "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			polls = append(polls, r.URL.RawQuery)
+			fmt.Fprintf(w, `[{"id":3,"price":"99","volume":"0.5","market":"btctwd","created_at":%d},
+				{"id":2,"price":"99","volume":"1","market":"btctwd","created_at":1530000000}]`, time.Now().Unix()+1)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL))
+	defer c.Close()
+
+	d := types.MustParseDecimal
+	paper := NewPaperClient(c, PaperBalance("twd", d("10000")), PaperFee(d("0")))
+	ctx := context.Background()
+
+	// Takes the 0.5 at 101, the next order only finds the level at 102.
+	first, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("0.5"), Price(d("102")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("0.5"), Price(d("102")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.AvgPrice.Equal(d("101")) || !second.AvgPrice.Equal(d("102")) {
+		t.Errorf("average prices %s and %s, want 101 and 102", first.AvgPrice, second.AvgPrice)
+	}
+
+	bid, err := paper.CreateOrder(ctx, "btctwd", OrderSideBuy, d("2"), Price(d("100")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paper.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 1 {
+		t.Fatalf("%d polls, want 1", len(polls))
+	}
+	if q, _ := url.ParseQuery(polls[0]); q.Get("from") != "" {
+		t.Errorf("poll query %q, want no from without a trade id", polls[0])
+	}
+
+	// Only the trade executed after the order fills it.
+	bid, _ = paper.Order(ctx, bid.ID)
+	if !bid.RemainingVolume.Equal(d("1.5")) {
+		t.Errorf("bid = %+v, want 1.5 remaining", bid)
+	}
+}
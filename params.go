@@ -19,31 +19,31 @@ import (
 )
 
 var (
-	DepositStateSubmitting      types.DepositState = "submitting"
-	DepositStateCancelled       types.DepositState = "cancelled"
-	DepositStateSubmitted       types.DepositState = "submitted"
-	DepositStateSuspended       types.DepositState = "suspended"
-	DepositStateRejected        types.DepositState = "rejected"
-	DepositStateAccepted        types.DepositState = "accepted"
-	DepositStateRefunded        types.DepositState = "refunded"
-	DepositStateSuspect         types.DepositState = "suspect"
-	DepositStateRefundCancelled types.DepositState = "refund_cancelled"
+	DepositStateSubmitting      = types.DepositStateSubmitting
+	DepositStateCancelled       = types.DepositStateCancelled
+	DepositStateSubmitted       = types.DepositStateSubmitted
+	DepositStateSuspended       = types.DepositStateSuspended
+	DepositStateRejected        = types.DepositStateRejected
+	DepositStateAccepted        = types.DepositStateAccepted
+	DepositStateRefunded        = types.DepositStateRefunded
+	DepositStateSuspect         = types.DepositStateSuspect
+	DepositStateRefundCancelled = types.DepositStateRefundCancelled
 )
 
 var (
-	WithdrawalStateSubmitting types.WithdrawalState = "submitting"
-	WithdrawalStateSubmitted  types.WithdrawalState = "submitted"
-	WithdrawalStateRejected   types.WithdrawalState = "rejected"
-	WithdrawalStateAccepted   types.WithdrawalState = "accepted"
-	WithdrawalStateSuspect    types.WithdrawalState = "suspect"
-	WithdrawalStateApproved   types.WithdrawalState = "approved"
-	WithdrawalStateProcessing types.WithdrawalState = "processing"
-	WithdrawalStateRetryable  types.WithdrawalState = "retryable"
-	WithdrawalStateSent       types.WithdrawalState = "sent"
-	WithdrawalStateCancelled  types.WithdrawalState = "cancelled"
-	WithdrawalStateFailed     types.WithdrawalState = "failed"
-	WithdrawalStatePending    types.WithdrawalState = "pending"
-	WithdrawalStateConfirmed  types.WithdrawalState = "confirmed"
+	WithdrawalStateSubmitting = types.WithdrawalStateSubmitting
+	WithdrawalStateSubmitted  = types.WithdrawalStateSubmitted
+	WithdrawalStateRejected   = types.WithdrawalStateRejected
+	WithdrawalStateAccepted   = types.WithdrawalStateAccepted
+	WithdrawalStateSuspect    = types.WithdrawalStateSuspect
+	WithdrawalStateApproved   = types.WithdrawalStateApproved
+	WithdrawalStateProcessing = types.WithdrawalStateProcessing
+	WithdrawalStateRetryable  = types.WithdrawalStateRetryable
+	WithdrawalStateSent       = types.WithdrawalStateSent
+	WithdrawalStateCancelled  = types.WithdrawalStateCancelled
+	WithdrawalStateFailed     = types.WithdrawalStateFailed
+	WithdrawalStatePending    = types.WithdrawalStatePending
+	WithdrawalStateConfirmed  = types.WithdrawalStateConfirmed
 )
 
 var (
@@ -54,13 +54,13 @@ var (
 )
 
 var (
-	OrderSideSell types.OrderSide = "sell"
-	OrderSideBuy  types.OrderSide = "buy"
+	OrderSideSell = types.OrderSideSell
+	OrderSideBuy  = types.OrderSideBuy
 )
 
 var (
-	OrderStateWait    types.OrderState = "wait"
-	OrderStateDone    types.OrderState = "done"
-	OrderStateConvert types.OrderState = "convert"
-	OrderStateCancel  types.OrderState = "cancel"
+	OrderStateWait    = types.OrderStateWait
+	OrderStateDone    = types.OrderStateDone
+	OrderStateConvert = types.OrderStateConvert
+	OrderStateCancel  = types.OrderStateCancel
 )
@@ -17,8 +17,6 @@ package max
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 
@@ -35,9 +33,14 @@ type privateClient = client
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) Me(ctx context.Context, opts ...CallOption) (*models.Member, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	member, _, err := c.c.PrivateApi.GetApiV2MembersMe(ctx, "", "", "")
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &member, err
+	return tmpMember(member).Member()
 }
 
 // Deposit returns details of the deposit with specific transaction ID.
@@ -47,9 +50,14 @@ func (c *privateClient) Me(ctx context.Context, opts ...CallOption) (*models.Mem
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) Deposit(ctx context.Context, txid string, opts ...CallOption) (*models.Deposit, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	deposit, _, err := c.c.PrivateApi.GetApiV2Deposit(ctx, "", "", "", txid)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &deposit, err
+	return tmpDeposit(deposit).Deposit()
 }
 
 // Deposits returns the history of your deposits.
@@ -68,19 +76,15 @@ func (c *privateClient) Deposit(ctx context.Context, txid string, opts ...CallOp
 //
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) Deposits(ctx context.Context, opts ...CallOption) (results []*models.Deposit, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *privateClient) Deposits(ctx context.Context, opts ...CallOption) ([]*models.Deposit, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	deposits, _, err := c.c.PrivateApi.GetApiV2Deposits(ctx, "", "", "", o)
-	for _, d := range deposits {
-		d := d
-		results = append(results, &d)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpDeposits(deposits).Deposits()
 }
 
 // Deprecated: Use DepositAddresses instead.
@@ -95,10 +99,7 @@ func (c *privateClient) Deposits(ctx context.Context, opts ...CallOption) (resul
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) DepositAddress(ctx context.Context, opts ...CallOption) (results []*models.PaymentAddress, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, o := callOptions(ctx, opts)
 
 	deposits, _, err := c.c.PrivateApi.GetApiV2DepositAddress(ctx, "", "", "", o)
 	for _, d := range deposits {
@@ -106,7 +107,7 @@ func (c *privateClient) DepositAddress(ctx context.Context, opts ...CallOption)
 		results = append(results, &d)
 	}
 
-	return results, err
+	return results, wrapError(err)
 }
 
 // DepositAddress returns the addresses that users are able to deposit.
@@ -118,10 +119,7 @@ func (c *privateClient) DepositAddress(ctx context.Context, opts ...CallOption)
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) DepositAddresses(ctx context.Context, opts ...CallOption) (results []*models.PaymentAddress, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, o := callOptions(ctx, opts)
 
 	deposits, _, err := c.c.PrivateApi.GetApiV2DepositAddresses(ctx, "", "", "", o)
 	for _, d := range deposits {
@@ -129,7 +127,7 @@ func (c *privateClient) DepositAddresses(ctx context.Context, opts ...CallOption
 		results = append(results, &d)
 	}
 
-	return results, err
+	return results, wrapError(err)
 }
 
 // CreateDepositAddresses creates new addresses for deposit.
@@ -141,13 +139,15 @@ func (c *privateClient) DepositAddresses(ctx context.Context, opts ...CallOption
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) CreateDepositAddresses(ctx context.Context, currency string, opts ...CallOption) (results []*models.PaymentAddress, err error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	deposits, _, err := c.c.PrivateApi.PostApiV2DepositAddresses(ctx, "", "", "", currency)
 	for _, d := range deposits {
 		d := d
 		results = append(results, &d)
 	}
 
-	return results, err
+	return results, wrapError(err)
 }
 
 // Withdrawals returns the withdrawals history.
@@ -166,19 +166,15 @@ func (c *privateClient) CreateDepositAddresses(ctx context.Context, currency str
 //
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) Withdrawals(ctx context.Context, opts ...CallOption) (results []*models.Withdrawal, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *privateClient) Withdrawals(ctx context.Context, opts ...CallOption) ([]*models.Withdrawal, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	withdrawals, _, err := c.c.PrivateApi.GetApiV2Withdrawals(ctx, "", "", "", o)
-	for _, w := range withdrawals {
-		w := w
-		results = append(results, &w)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpWithdrawals(withdrawals).Withdrawals()
 }
 
 // Withdrawal returns the details of specific withdrawal.
@@ -188,14 +184,14 @@ func (c *privateClient) Withdrawals(ctx context.Context, opts ...CallOption) (re
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) Withdrawal(ctx context.Context, uuid string, opts ...CallOption) (*models.Withdrawal, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, _ = callOptions(ctx, opts)
 
 	withdrawal, _, err := c.c.PrivateApi.GetApiV2Withdrawal(ctx, "", "", "", uuid)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &withdrawal, err
+	return tmpWithdrawal(withdrawal).Withdrawal()
 }
 
 // CreateOrder creates a sell/buy order.
@@ -209,29 +205,49 @@ func (c *privateClient) Withdrawal(ctx context.Context, uuid string, opts ...Cal
 //    StopPrice(): price per unit to trigger a stop order
 //    OrderType(): `OrderTypeLimit`, `OrderTypeMarket`, `OrderTypeStopLimit`, or `OrderTypeStopMarket`
 //
+// The order is checked against the market precision first if ValidateOrders() is enabled.
+//
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) CreateOrder(ctx context.Context, market string, side string, volumes types.Volume, opts ...CallOption) (*models.Order, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
+func (c *privateClient) CreateOrder(ctx context.Context, market string, side types.OrderSide, volumes types.Volume, opts ...CallOption) (*models.Order, error) {
+	ctx, o := callOptions(ctx, opts)
+
+	if c.validator != nil {
+		var err error
+		if volumes, err = c.validator.validateOptions(ctx, market, side, volumes, o); err != nil {
+			return nil, err
+		}
 	}
 
-	order, _, err := c.c.PrivateApi.PostApiV2Orders(ctx, "", "", "", market, side, fmt.Sprintf("%v", volumes), o)
+	order, _, err := c.c.PrivateApi.PostApiV2Orders(ctx, "", "", "", market, string(side), volumes.String(), o)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &order, err
+	return tmpOrder(order).Order()
 }
 
 // CreateOrders creates multiple sell/buy orders.
 //
 // Available `CallOption`:
 //
+// The orders are checked against the market precision first if ValidateOrders() is enabled.
+//
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) CreateOrders(ctx context.Context, market string, orderRequests []*models.OrderRequest, opts ...CallOption) (results []*models.Order, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
+func (c *privateClient) CreateOrders(ctx context.Context, market string, orderRequests []*models.OrderRequest, opts ...CallOption) ([]*models.Order, error) {
+	ctx, _ = callOptions(ctx, opts)
+
+	if c.validator != nil {
+		validated := make([]*models.OrderRequest, len(orderRequests))
+		for i, req := range orderRequests {
+			r, err := c.validator.validate(ctx, market, *req)
+			if err != nil {
+				return nil, err
+			}
+			validated[i] = &r
+		}
+		orderRequests = validated
 	}
 
 	body := make(map[string]interface{})
@@ -255,16 +271,17 @@ func (c *privateClient) CreateOrders(ctx context.Context, market string, orderRe
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Status: %v, Body: %s", resp.Status, bodyBytes)
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return results, err
+	orders := tmpOrders{}
+	if err = json.NewDecoder(resp.Body).Decode(&orders); err != nil {
+		return nil, err
 	}
 
-	return results, err
+	return orders.Orders()
 }
 
 // CancelOrder cancels a sell/buy order.
@@ -274,14 +291,14 @@ func (c *privateClient) CreateOrders(ctx context.Context, market string, orderRe
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) CancelOrder(ctx context.Context, id int32, opts ...CallOption) (*models.Order, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, _ = callOptions(ctx, opts)
 
 	order, _, err := c.c.PrivateApi.PostApiV2OrderDelete(ctx, "", "", "", id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &order, err
+	return tmpOrder(order).Order()
 }
 
 // CancelOrders cancels a series of sell/buy orders.
@@ -292,18 +309,15 @@ func (c *privateClient) CancelOrder(ctx context.Context, id int32, opts ...CallO
 //
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) CancelOrders(ctx context.Context, opts ...CallOption) (results []*models.Order, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *privateClient) CancelOrders(ctx context.Context, opts ...CallOption) ([]*models.Order, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	orders, _, err := c.c.PrivateApi.PostApiV2OrdersClear(ctx, "", "", "", o)
-	for _, order := range orders {
-		results = append(results, &order)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpOrders(orders).Orders()
 }
 
 // Order returns details of a specific order.
@@ -313,15 +327,20 @@ func (c *privateClient) CancelOrders(ctx context.Context, opts ...CallOption) (r
 // Note:
 //     Use AuthToken() to pass your auth tokens.
 func (c *privateClient) Order(ctx context.Context, id int32, opts ...CallOption) (*models.Order, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	order, _, err := c.c.PrivateApi.GetApiV2Order(ctx, "", "", "", id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &order, err
+	return tmpOrder(order).Order()
 }
 
 // Orders returns your orders.
 //
 // Available `CallOption`:
-//     State(): filter by state, default to 'OrderStateWait'
+//     OrderState(): filter by state, default to 'OrderStateWait'
 //     OrderDesc(): use descending order by created time
 //     OrderAsc(): use ascending order by created time, default value
 //     Pagination(): do pagination & return metadata in header (default true)
@@ -331,19 +350,15 @@ func (c *privateClient) Order(ctx context.Context, id int32, opts ...CallOption)
 //
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) Orders(ctx context.Context, market string, opts ...CallOption) (results []*models.Order, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *privateClient) Orders(ctx context.Context, market string, opts ...CallOption) ([]*models.Order, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	orders, _, err := c.c.PrivateApi.GetApiV2Orders(ctx, "", "", "", market, o)
-	for _, order := range orders {
-		order := order
-		results = append(results, &order)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpOrders(orders).Orders()
 }
 
 // MyTrades returns the executed trades which are sorted in reverse creation order.
@@ -362,17 +377,13 @@ func (c *privateClient) Orders(ctx context.Context, market string, opts ...CallO
 //
 // Note:
 //     Use AuthToken() to pass your auth tokens.
-func (c *privateClient) MyTrades(ctx context.Context, market string, opts ...CallOption) (results []*models.Trade, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *privateClient) MyTrades(ctx context.Context, market string, opts ...CallOption) ([]*models.Trade, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	trades, _, err := c.c.PrivateApi.GetApiV2TradesMy(ctx, "", "", "", market, o)
-	for _, t := range trades {
-		t := t
-		results = append(results, &t)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpTrades(trades).Trades()
 }
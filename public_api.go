@@ -29,6 +29,8 @@ type publicClient = client
 // Available `CallOption`:
 //
 func (c *publicClient) Markets(ctx context.Context, opts ...CallOption) (results []*models.Market, err error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	markets, _, err := c.c.PublicApi.GetApiV2Markets(ctx)
 
 	for _, m := range markets {
@@ -36,7 +38,7 @@ func (c *publicClient) Markets(ctx context.Context, opts ...CallOption) (results
 		results = append(results, &m)
 	}
 
-	return results, err
+	return results, wrapError(err)
 }
 
 // Markets returns available currencies on MAX.
@@ -44,6 +46,8 @@ func (c *publicClient) Markets(ctx context.Context, opts ...CallOption) (results
 // Available `CallOption`:
 //
 func (c *publicClient) Currencies(ctx context.Context, opts ...CallOption) (results []*models.Currency, err error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	currencies, _, err := c.c.PublicApi.GetApiV2Currencies(ctx)
 
 	for _, c := range currencies {
@@ -51,7 +55,7 @@ func (c *publicClient) Currencies(ctx context.Context, opts ...CallOption) (resu
 		results = append(results, &c)
 	}
 
-	return results, err
+	return results, wrapError(err)
 }
 
 // Ticker returns a ticker of specific market.
@@ -59,9 +63,11 @@ func (c *publicClient) Currencies(ctx context.Context, opts ...CallOption) (resu
 // Available `CallOption`:
 //
 func (c *publicClient) Ticker(ctx context.Context, market string, opts ...CallOption) (*models.Ticker, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	ticker, _, err := c.c.PublicApi.GetApiV2TickersMarket(ctx, market)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return tmpTicker(ticker).Ticker()
@@ -72,9 +78,11 @@ func (c *publicClient) Ticker(ctx context.Context, market string, opts ...CallOp
 // Available `CallOption`:
 //
 func (c *publicClient) Tickers(ctx context.Context, opts ...CallOption) (models.Tickers, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	tickers, _, err := c.c.PublicApi.GetApiV2Tickers(ctx)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	tt := tmpTickers{}
@@ -92,14 +100,14 @@ func (c *publicClient) Tickers(ctx context.Context, opts ...CallOption) (models.
 //     AsksLimit(): returned sell orders limit, default to 20
 //     BidsLimit(): returned buy orders limit, default to 20
 func (c *publicClient) OrderBook(ctx context.Context, market string, opts ...CallOption) (*models.OrderBook, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, o := callOptions(ctx, opts)
 
 	orderbook, _, err := c.c.PublicApi.GetApiV2OrderBook(ctx, market, o)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return &orderbook, err
+	return tmpOrderBook(orderbook).OrderBook()
 }
 
 // Depth returns depth of specific market.
@@ -107,10 +115,7 @@ func (c *publicClient) OrderBook(ctx context.Context, market string, opts ...Cal
 // Available `CallOption`:
 //     Limit(): returned price levels limit, default to 300
 func (c *publicClient) Depth(ctx context.Context, market string, opts ...CallOption) (*models.Depth, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, o := callOptions(ctx, opts)
 
 	resp, err := c.c.PublicApi.GetApiV2Depth(ctx, market, o)
 	if err != nil {
@@ -118,6 +123,10 @@ func (c *publicClient) Depth(ctx context.Context, market string, opts ...CallOpt
 	}
 	defer resp.Body.Close()
 
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
 	depth := &depthJSON{}
 	err = json.NewDecoder(resp.Body).Decode(&depth)
 	if err != nil {
@@ -140,19 +149,15 @@ func (c *publicClient) Depth(ctx context.Context, market string, opts ...CallOpt
 //     Page(): page number, applied for pagination (default 1)
 //     Limit(): returned limit (1~1000, default 50)
 //     Offset(): records to skip, not applied for pagination (default 0)
-func (c *publicClient) Trades(ctx context.Context, market string, opts ...CallOption) (results []*models.Trade, err error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+func (c *publicClient) Trades(ctx context.Context, market string, opts ...CallOption) ([]*models.Trade, error) {
+	ctx, o := callOptions(ctx, opts)
 
 	trades, _, err := c.c.PublicApi.GetApiV2Trades(ctx, market, o)
-	for _, t := range trades {
-		t := t
-		results = append(results, &t)
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
-	return results, err
+	return tmpTrades(trades).Trades()
 }
 
 // K returns OHLC chart of specific market.
@@ -164,10 +169,7 @@ func (c *publicClient) Trades(ctx context.Context, market string, opts ...CallOp
 //     PeriodDuration(): time period of K line in time.Duration format, default to 1*time.Minute
 //     Limit(): returned data points limit, default to 30
 func (c *publicClient) K(ctx context.Context, market string, opts ...CallOption) ([]*models.Candle, error) {
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(o)
-	}
+	ctx, o := callOptions(ctx, opts)
 
 	resp, err := c.c.PublicApi.GetApiV2K(ctx, market, o)
 	if err != nil {
@@ -175,6 +177,10 @@ func (c *publicClient) K(ctx context.Context, market string, opts ...CallOption)
 	}
 	defer resp.Body.Close()
 
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
 	candles := candlesJSON{}
 	err = json.NewDecoder(resp.Body).Decode(&candles)
 	if err != nil {
@@ -189,12 +195,18 @@ func (c *publicClient) K(ctx context.Context, market string, opts ...CallOption)
 // Available `CallOption`:
 //
 func (c *publicClient) Time(ctx context.Context, opts ...CallOption) (time.Time, error) {
+	ctx, _ = callOptions(ctx, opts)
+
 	resp, err := c.c.PublicApi.GetApiV2Timestamp(ctx)
 	if err != nil {
 		return time.Time{}, err
 	}
 	defer resp.Body.Close()
 
+	if err := checkResponse(resp); err != nil {
+		return time.Time{}, err
+	}
+
 	var t int64
 	err = json.NewDecoder(resp.Body).Decode(&t)
 	if err != nil {
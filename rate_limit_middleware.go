@@ -0,0 +1,297 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitGroup is a group of endpoints sharing the same request budget.
+type RateLimitGroup string
+
+const (
+	// RateLimitGroupOrders covers order creation and cancellation.
+	RateLimitGroupOrders RateLimitGroup = "orders"
+	// RateLimitGroupQueries covers the other private endpoints.
+	RateLimitGroupQueries RateLimitGroup = "queries"
+	// RateLimitGroupPublic covers the public endpoints.
+	RateLimitGroupPublic RateLimitGroup = "public"
+)
+
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+
+	minRateLimitPenalty = 1 * time.Second
+	maxRateLimitPenalty = 30 * time.Second
+)
+
+// RateLimit is the token bucket budget of a RateLimitGroup.
+type RateLimit struct {
+	// Rate is the number of requests allowed per second.
+	Rate float64
+	// Burst is the maximum number of requests sent at once.
+	Burst int
+}
+
+// RateLimits maps endpoint groups to their budgets.
+type RateLimits map[RateLimitGroup]RateLimit
+
+// DefaultRateLimits returns conservative budgets for the MAX APIs.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		RateLimitGroupOrders:  {Rate: 5, Burst: 10},
+		RateLimitGroupQueries: {Rate: 5, Burst: 10},
+		RateLimitGroupPublic:  {Rate: 10, Burst: 20},
+	}
+}
+
+var publicPaths = map[string]bool{
+	"/api/v2/currencies": true,
+	"/api/v2/depth":      true,
+	"/api/v2/k":          true,
+	"/api/v2/markets":    true,
+	"/api/v2/order_book": true,
+	"/api/v2/tickers":    true,
+	"/api/v2/timestamp":  true,
+	"/api/v2/trades":     true,
+}
+
+func rateLimitGroupOf(req *http.Request) RateLimitGroup {
+	path := req.URL.Path
+
+	if publicPaths[path] || strings.HasPrefix(path, "/api/v2/tickers/") {
+		return RateLimitGroupPublic
+	}
+
+	if req.Method != http.MethodGet && strings.HasPrefix(path, "/api/v2/order") {
+		return RateLimitGroupOrders
+	}
+
+	return RateLimitGroupQueries
+}
+
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	penalty     time.Duration
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// reserve takes a token, or returns how long to wait for one.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(now)
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	if b.rate <= 0 {
+		return maxRateLimitPenalty
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		d := b.reserve()
+		if d == 0 {
+			return nil
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *tokenBucket) budget() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(now)
+	if now.Before(b.pausedUntil) {
+		return 0
+	}
+
+	return b.tokens
+}
+
+// pause empties the bucket until the given time.
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = 0
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// observe adapts the bucket to the rate limit feedback of the server.
+func (b *tokenBucket) observe(resp *http.Response) {
+	now := time.Now()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.mu.Lock()
+		if b.penalty == 0 {
+			b.penalty = minRateLimitPenalty
+		} else {
+			b.penalty *= 2
+		}
+		if b.penalty > maxRateLimitPenalty {
+			b.penalty = maxRateLimitPenalty
+		}
+		wait := b.penalty
+		b.mu.Unlock()
+
+		if after, ok := retryAfter(resp); ok {
+			wait = after
+			if wait > maxRateLimitPenalty {
+				wait = maxRateLimitPenalty
+			}
+		}
+		b.pause(now.Add(wait))
+		return
+	}
+
+	b.mu.Lock()
+	b.penalty = 0
+	b.mu.Unlock()
+
+	remaining, err := strconv.ParseFloat(resp.Header.Get(headerRateLimitRemaining), 64)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	if remaining < b.tokens {
+		b.tokens = remaining
+	}
+	b.mu.Unlock()
+
+	if remaining < 1 {
+		if reset, ok := rateLimitReset(resp, now); ok {
+			b.pause(reset)
+		}
+	}
+}
+
+// rateLimitReset parses the reset header, either in seconds from now
+// or as seconds since Unix epoch.
+func rateLimitReset(resp *http.Response, now time.Time) (time.Time, bool) {
+	v, err := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if v > 1000000000 {
+		return time.Unix(v, 0), true
+	}
+
+	return now.Add(time.Duration(v) * time.Second), true
+}
+
+type rateLimiter struct {
+	buckets map[RateLimitGroup]*tokenBucket
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	l := &rateLimiter{
+		buckets: make(map[RateLimitGroup]*tokenBucket),
+	}
+
+	for group, limit := range limits {
+		l.buckets[group] = newTokenBucket(limit)
+	}
+
+	return l
+}
+
+func newRateLimitMiddleware(l *rateLimiter) middleware {
+	return func(n http.RoundTripper) http.RoundTripper {
+		return rateLimitMiddleware{
+			limiter: l,
+			next:    n,
+		}
+	}
+}
+
+type rateLimitMiddleware struct {
+	limiter *rateLimiter
+	next    http.RoundTripper
+}
+
+func (m rateLimitMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+	b, ok := m.limiter.buckets[rateLimitGroupOf(req)]
+	if !ok {
+		return m.next.RoundTrip(req)
+	}
+
+	if err := b.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := m.next.RoundTrip(req)
+	if err == nil {
+		b.observe(resp)
+	}
+
+	return resp, err
+}
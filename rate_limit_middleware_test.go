@@ -0,0 +1,87 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "60")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`1536000000`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), RateLimiter(RateLimits{
+		RateLimitGroupPublic: {Rate: 1, Burst: 1},
+	}))
+	defer c.Close()
+
+	if _, err := c.Time(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if budget, _ := c.RateLimitBudget(RateLimitGroupPublic); budget >= 1 {
+		t.Errorf("budget = %v, want < 1", budget)
+	}
+	if _, ok := c.RateLimitBudget(RateLimitGroupOrders); ok {
+		t.Error("orders group should not be rate limited")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.Time(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	time.Sleep(time.Second)
+	status = http.StatusTooManyRequests
+	if _, err := c.Time(context.Background()); !IsRateLimited(err) {
+		t.Errorf("err = %v, want rate limited", err)
+	}
+
+	time.Sleep(time.Second)
+	if budget, _ := c.RateLimitBudget(RateLimitGroupPublic); budget != 0 {
+		t.Errorf("budget = %v after 429, want 0", budget)
+	}
+}
+
+func TestRateLimitPenaltyIsCapped(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+
+	for i := 0; i < 10; i++ {
+		b.observe(resp)
+	}
+	if b.penalty != maxRateLimitPenalty {
+		t.Errorf("penalty = %v, want %v", b.penalty, maxRateLimitPenalty)
+	}
+
+	resp.Header.Set("Retry-After", "36000")
+	b.observe(resp)
+	if wait := time.Until(b.pausedUntil); wait > maxRateLimitPenalty {
+		t.Errorf("paused for %v, want at most %v", wait, maxRateLimitPenalty)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+// RecordFileExt is the extension of the files written by a Recorder.
+const RecordFileExt = ".jsonl.gz"
+
+// ErrRecorderClosed is returned when recording with a closed Recorder.
+var ErrRecorderClosed = errors.New("max: recorder closed")
+
+// record is a line of the recorded files.
+type record struct {
+	// receive time of the frame
+	At time.Time `json:"at"`
+	// websocket frame as received
+	Frame  string        `json:"frame"`
+	Events []recordEvent `json:"events,omitempty"`
+}
+
+// recordEvent is an event decoded from the frame, in its models JSON.
+type recordEvent struct {
+	Channel string          `json:"channel"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// eventChannel returns the channel of a decoded event.
+func eventChannel(ev interface{}) string {
+	switch ev.(type) {
+	case *models.TickerEvent:
+		return ChannelTicker
+	case *models.OrderBookEvent:
+		return ChannelOrderBook
+	case *models.TradeEvent:
+		return ChannelTrade
+	case *models.AccountEvent:
+		return "account"
+	case *models.OrderEvent:
+		return "order"
+	case *models.Trade:
+		return "my_trade"
+	}
+
+	return ""
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// RecordRotateEvery starts a new file every period, default to an hour.
+func RecordRotateEvery(period time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		r.period = period
+	}
+}
+
+// RecordMaxSize starts a new file once size bytes, before compression,
+// are written to the current one. Disabled by default.
+func RecordMaxSize(size int64) RecorderOption {
+	return func(r *Recorder) {
+		r.maxSize = size
+	}
+}
+
+// Recorder writes the websocket frames received by a client, and the
+// events decoded from them, to gzipped JSON lines files rotated by time
+// and size. Pass it to the client with WSRecorder(), and read the files
+// back with a Replayer.
+//
+//	recorder, err := max.NewRecorder("data/btctwd")
+//	client, err := max.NewWSClient(max.WSRecorder(recorder))
+//	...
+//	client.Close()
+//	recorder.Close()
+type Recorder struct {
+	dir     string
+	period  time.Duration
+	maxSize int64
+
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	openedAt time.Time
+	size     int64
+	closed   bool
+}
+
+// NewRecorder returns a Recorder writing files in dir, created if needed.
+func NewRecorder(dir string, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		dir:    dir,
+		period: time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Close flushes and closes the current file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	return r.closeFile()
+}
+
+// RecordedFiles returns the files recorded in dir by a Recorder, oldest first.
+func RecordedFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+RecordFileExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func (r *Recorder) record(at time.Time, frame []byte, events []interface{}) error {
+	rec := record{At: at, Frame: string(frame)}
+	for _, ev := range events {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		rec.Events = append(rec.Events, recordEvent{Channel: eventChannel(ev), Event: b})
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRecorderClosed
+	}
+
+	if r.gz != nil && (at.Sub(r.openedAt) >= r.period || (r.maxSize > 0 && r.size >= r.maxSize)) {
+		if err := r.closeFile(); err != nil {
+			return err
+		}
+	}
+	if r.gz == nil {
+		if err := r.openFile(at); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.gz.Write(line)
+	r.size += int64(n)
+
+	return err
+}
+
+func (r *Recorder) openFile(at time.Time) error {
+	// The names sort in time order, the suffix avoids collisions when
+	// rotating by size.
+	name := "max-" + at.UTC().Format("20060102T150405.000000000Z")
+	path := filepath.Join(r.dir, name+RecordFileExt)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(r.dir, fmt.Sprintf("%s-%d%s", name, i, RecordFileExt))
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.file = f
+	r.gz = gzip.NewWriter(f)
+	r.openedAt = at
+	r.size = 0
+
+	return nil
+}
+
+func (r *Recorder) closeFile() error {
+	if r.gz == nil {
+		return nil
+	}
+
+	err := r.gz.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.gz = nil
+	r.file = nil
+
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "max-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recorder, err := NewRecorder(dir, RecordMaxSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	c, err := NewWSClient(WSURL(srv.URL), WSRecorder(recorder), WSLogging(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err := c.SubscribeTrade("btctwd", make(chan *models.TradeEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := <-srv.conns
+	srv.write(conn, map[string]interface{}{"info": "trade", "market": "btctwd", "at": 1000, "price": "100", "volume": "1"})
+	conn.WriteMessage(1, []byte("{not json"))
+	srv.write(conn, map[string]interface{}{"info": "trade", "market": "btctwd", "at": 2000, "price": "101", "volume": "2"})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-trades.Chan():
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for trades")
+		}
+	}
+	c.Close()
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The challenge, two trades and the malformed frame, one per file.
+	files, err := RecordedFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("%d files recorded, want 4", len(files))
+	}
+
+	replayer := NewReplayer(ReplayFastest, files...)
+	defer replayer.Close()
+
+	replayed, _ := replayer.SubscribeTrade("btctwd", make(chan *models.TradeEvent, 10))
+	others, _ := replayer.SubscribeTrade("ethtwd", make(chan *models.TradeEvent, 10))
+	if err := replayer.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, price := range []string{"100", "101"} {
+		select {
+		case ev := <-replayed.Chan():
+			if ev.Price.String() != price || ev.At.UnixNano() == 0 {
+				t.Errorf("replayed trade %+v, want price %s", ev, price)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for replayed trades")
+		}
+	}
+	if n := len(others.Chan()); n != 0 {
+		t.Errorf("%d ethtwd trades replayed, want none", n)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+)
+
+// Replay speeds, any other positive speed accelerates or slows down the replay.
+const (
+	// ReplayFastest replays the events without waiting.
+	ReplayFastest float64 = 0
+	// ReplayRealTime replays the events at the pace they were received.
+	ReplayRealTime float64 = 1
+)
+
+// Replayer feeds the events of files written by a Recorder to ticker,
+// order book and trade subscriptions, like the websocket client does.
+//
+//	files, err := max.RecordedFiles("data/btctwd")
+//	replayer := max.NewReplayer(10, files...)
+//	sub, err := replayer.SubscribeTicker("btctwd", make(chan *models.TickerEvent, 10))
+//	go replayer.Run(ctx)
+type Replayer struct {
+	files []string
+	speed float64
+	bus   *dispatcher
+
+	closers   map[*subscriber]func()
+	closersMu sync.Mutex
+}
+
+// NewReplayer returns a replayer of the files, in the given order, at the
+// given speed, e.g. ReplayRealTime or 10 to replay 10 times faster.
+func NewReplayer(speed float64, files ...string) *Replayer {
+	return &Replayer{
+		files:   files,
+		speed:   speed,
+		bus:     newDispatcher(),
+		closers: make(map[*subscriber]func()),
+	}
+}
+
+// Run replays the files, and returns once all the events are delivered to
+// the subscription buffers, or when ctx is done. It can be called again to
+// replay the files another time.
+func (r *Replayer) Run(ctx context.Context) error {
+	var first, start time.Time
+
+	for _, path := range r.files {
+		err := r.replayFile(ctx, path, func(rec *record) error {
+			if first.IsZero() {
+				first, start = rec.At, time.Now()
+			}
+
+			if r.speed > 0 {
+				elapsed := time.Duration(float64(rec.At.Sub(first)) / r.speed)
+				if wait := time.Until(start.Add(elapsed)); wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-timer.C:
+					case <-ctx.Done():
+						timer.Stop()
+						return ctx.Err()
+					}
+				}
+			}
+
+			return r.publish(rec)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Replayer) replayFile(ctx context.Context, path string, fn func(*record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	br := bufio.NewReader(reader)
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err == io.EOF && len(b) == 0 {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		rec := &record{}
+		if err := json.Unmarshal(b, rec); err != nil {
+			return fmt.Errorf("max: %s:%d: %v", path, line, err)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// publish dispatches the public events of a record.
+func (r *Replayer) publish(rec *record) error {
+	for _, e := range rec.Events {
+		var (
+			ev     interface{}
+			market string
+		)
+
+		switch e.Channel {
+		case ChannelTicker:
+			t := &models.TickerEvent{}
+			ev = t
+			if err := json.Unmarshal(e.Event, t); err != nil {
+				return err
+			}
+			market = t.Market
+		case ChannelOrderBook:
+			ob := &models.OrderBookEvent{}
+			ev = ob
+			if err := json.Unmarshal(e.Event, ob); err != nil {
+				return err
+			}
+			market = ob.Market
+		case ChannelTrade:
+			t := &models.TradeEvent{}
+			ev = t
+			if err := json.Unmarshal(e.Event, t); err != nil {
+				return err
+			}
+			market = t.Market
+		default:
+			continue
+		}
+
+		r.bus.Publish(toTopic(e.Channel, map[string]interface{}{
+			"market": market,
+		}), ev)
+	}
+
+	return nil
+}
+
+// Close closes the channels of all the subscriptions.
+func (r *Replayer) Close() {
+	r.closersMu.Lock()
+	closers := make([]func(), 0, len(r.closers))
+	for _, fn := range r.closers {
+		closers = append(closers, fn)
+	}
+	r.closersMu.Unlock()
+
+	for _, fn := range closers {
+		fn()
+	}
+}
+
+// SubscribeTicker subscribes the recorded tickers of a market.
+//
+// Available `SubscribeOption`:
+//
+//	WithBuffer()
+func (r *Replayer) SubscribeTicker(market string, ch chan *models.TickerEvent, opts ...SubscribeOption) (TickerSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.TickerEvent):
+		case <-done:
+		}
+	}
+
+	sub, unsubscribe := r.subscribe(ChannelTicker, market, send, opts, func() { close(ch) })
+
+	return &tickerSubscription{
+		ch:          ch,
+		sub:         sub,
+		unsubscribe: unsubscribe,
+	}, nil
+}
+
+// SubscribeOrderBook subscribes the recorded order book changes of a market.
+//
+// Available `SubscribeOption`:
+//
+//	WithBuffer()
+func (r *Replayer) SubscribeOrderBook(market string, ch chan *models.OrderBookEvent, opts ...SubscribeOption) (OrderBookSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.OrderBookEvent):
+		case <-done:
+		}
+	}
+
+	sub, unsubscribe := r.subscribe(ChannelOrderBook, market, send, opts, func() { close(ch) })
+
+	return &orderBookSubscription{
+		ch:          ch,
+		sub:         sub,
+		unsubscribe: unsubscribe,
+	}, nil
+}
+
+// SubscribeTrade subscribes the recorded trades of a market.
+//
+// Available `SubscribeOption`:
+//
+//	WithBuffer()
+func (r *Replayer) SubscribeTrade(market string, ch chan *models.TradeEvent, opts ...SubscribeOption) (TradeSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.TradeEvent):
+		case <-done:
+		}
+	}
+
+	sub, unsubscribe := r.subscribe(ChannelTrade, market, send, opts, func() { close(ch) })
+
+	return &tradeSubscription{
+		ch:          ch,
+		sub:         sub,
+		unsubscribe: unsubscribe,
+	}, nil
+}
+
+// subscribe registers a subscriber, and returns the idempotent function
+// unsubscribing it and closing its channel.
+func (r *Replayer) subscribe(channel, market string, send func(interface{}, <-chan struct{}), opts []SubscribeOption, closeCh func()) (*subscriber, func()) {
+	o := subscribeOptions{size: defaultBufferSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sub := newSubscriber(o, send)
+	topic := toTopic(channel, map[string]interface{}{
+		"market": market,
+	})
+	r.bus.Subscribe(topic, sub)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			r.closersMu.Lock()
+			delete(r.closers, sub)
+			r.closersMu.Unlock()
+
+			r.bus.Unsubscribe(topic, sub)
+			r.bus.Release(sub)
+			closeCh()
+		})
+	}
+
+	r.closersMu.Lock()
+	r.closers[sub] = unsubscribe
+	r.closersMu.Unlock()
+
+	return sub, unsubscribe
+}
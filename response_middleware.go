@@ -0,0 +1,97 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const HeaderRequestID = "X-Request-Id"
+
+// ResponseMeta holds the HTTP response metadata of a call, see WithResponse().
+type ResponseMeta struct {
+	// HTTP status code
+	StatusCode int
+	// response headers, including pagination and rate limit headers
+	Header http.Header
+	// time spent from sending the request to reading the whole body,
+	// including retries
+	Latency time.Duration
+	// raw response body
+	Body []byte
+}
+
+// RequestID returns the request id set by the server.
+func (m *ResponseMeta) RequestID() string {
+	return m.Header.Get(HeaderRequestID)
+}
+
+// Date returns the server time in the Date header.
+func (m *ResponseMeta) Date() (time.Time, error) {
+	return http.ParseTime(m.Header.Get("Date"))
+}
+
+// Total returns the total number of records in the pagination headers.
+func (m *ResponseMeta) Total() (int, bool) {
+	total, err := strconv.Atoi(m.Header.Get(HeaderTotal))
+	return total, err == nil
+}
+
+type responseMetaKey struct{}
+
+func newResponseMiddleware() middleware {
+	return func(n http.RoundTripper) http.RoundTripper {
+		return responseMiddleware{
+			next: n,
+		}
+	}
+}
+
+// responseMiddleware fills the ResponseMeta carried by the request context.
+type responseMiddleware struct {
+	next http.RoundTripper
+}
+
+func (m responseMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+	meta, ok := req.Context().Value(responseMetaKey{}).(*ResponseMeta)
+	if !ok {
+		return m.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := m.next.RoundTrip(req)
+	if err != nil {
+		meta.Latency = time.Since(start)
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	meta.Latency = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	meta.StatusCode = resp.StatusCode
+	meta.Header = resp.Header
+	meta.Body = body
+
+	return resp, nil
+}
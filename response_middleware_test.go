@@ -0,0 +1,78 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRequestID, "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":2004,"message":"Order#1 doesn't exist."}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"))
+	defer c.Close()
+
+	meta := &ResponseMeta{}
+	_, err := c.Order(context.Background(), 1, WithResponse(meta))
+	if !IsOrderNotFound(err) {
+		t.Errorf("err = %v, want order not found", err)
+	}
+	if meta.StatusCode != http.StatusNotFound || meta.RequestID() != "req-1" {
+		t.Errorf("meta = %d %s", meta.StatusCode, meta.RequestID())
+	}
+	if !strings.Contains(string(meta.Body), "2004") {
+		t.Errorf("Body = %s", meta.Body)
+	}
+	if meta.Latency <= 0 {
+		t.Error("Latency is not measured")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+type bodyRoundTripper struct{ body io.Reader }
+
+func (rt bodyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(rt.body)}, nil
+}
+
+func TestResponseMiddlewareReadError(t *testing.T) {
+	meta := &ResponseMeta{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/markets", nil)
+	req = req.WithContext(context.WithValue(req.Context(), responseMetaKey{}, meta))
+
+	rt := newResponseMiddleware()(bodyRoundTripper{io.MultiReader(strings.NewReader("[{"), errReader{})})
+	resp, err := rt.RoundTrip(req)
+	if resp != nil || err == nil {
+		t.Errorf("RoundTrip() = %v, %v, want only an error", resp, err)
+	}
+	if meta.Body != nil {
+		t.Errorf("meta.Body = %q, want nothing recorded", meta.Body)
+	}
+}
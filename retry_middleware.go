@@ -0,0 +1,199 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed REST calls are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the base delay of the exponential backoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including the delays
+	// asked by Retry-After headers.
+	MaxBackoff time.Duration
+	// RetryableStatusCodes lists the HTTP status codes worth retrying.
+	RetryableStatusCodes []int
+	// Methods lists the HTTP methods allowed to be retried.
+	Methods []string
+}
+
+// DefaultRetryPolicy returns a policy retrying GET requests up to 3 times
+// on 429 and 5xx gateway errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  200 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{http.MethodGet},
+	}
+}
+
+func (p RetryPolicy) retryableMethod(method string) bool {
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (starting from 1),
+// using exponential backoff with full jitter.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff << uint(retry-1)
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// capRetryAfter bounds the delay asked by a Retry-After header to MaxBackoff.
+func (p RetryPolicy) capRetryAfter(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// newRetryMiddleware must wrap the auth middleware, so that every attempt
+// is signed again with a fresh nonce.
+func newRetryMiddleware(policy RetryPolicy) middleware {
+	return func(n http.RoundTripper) http.RoundTripper {
+		return retryMiddleware{
+			policy: policy,
+			next:   n,
+		}
+	}
+}
+
+type retryMiddleware struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+}
+
+func (m retryMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+	if m.policy.MaxAttempts <= 1 || !m.policy.retryableMethod(req.Method) {
+		return m.next.RoundTrip(req)
+	}
+
+	// Keep the unsigned body, the auth middleware replaces it on every attempt.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := m.next.RoundTrip(r)
+		if attempt >= m.policy.MaxAttempts || !m.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := m.policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = m.policy.capRetryAfter(after)
+			}
+		}
+
+		// Do not wait for a retry which cannot happen before the deadline.
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (m retryMiddleware) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	return m.policy.retryableStatus(resp.StatusCode)
+}
+
+// retryAfter parses the Retry-After header in either seconds or HTTP date format.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
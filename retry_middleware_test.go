@@ -0,0 +1,113 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryResignsRequests(t *testing.T) {
+	var nonces []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := base64.StdEncoding.DecodeString(r.Header.Get(HeaderPayloadKey))
+		params := struct {
+			Nonce int64 `json:"nonce"`
+		}{}
+		json.Unmarshal(payload, &params)
+		nonces = append(nonces, params.Nonce)
+
+		if len(nonces) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"sn":"MAX"}`))
+	}))
+	defer srv.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"), Retry(policy))
+	defer c.Close()
+
+	member, err := c.Me(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Sn != "MAX" {
+		t.Errorf("Sn = %s, want MAX", member.Sn)
+	}
+	if len(nonces) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(nonces))
+	}
+	if nonces[0] == nonces[1] || nonces[1] == nonces[2] {
+		t.Errorf("nonces are reused across attempts: %v", nonces)
+	}
+}
+
+func TestRetrySkipsPost(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"), Retry(DefaultRetryPolicy()))
+	defer c.Close()
+
+	if _, err := c.CancelOrder(context.Background(), 1); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestRetryCapsRetryAfter(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "36000")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"sn":"MAX"}`))
+	}))
+	defer srv.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MaxBackoff = 10 * time.Millisecond
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"), Retry(policy))
+	defer c.Close()
+
+	start := time.Now()
+	if _, err := c.Me(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v for Retry-After, want at most MaxBackoff", elapsed)
+	}
+	if attempts != 2 {
+		t.Errorf("got %d attempts, want 2", attempts)
+	}
+}
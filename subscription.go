@@ -40,11 +40,13 @@ func toTopic(channel interface{}, params interface{}) string {
 type TickerSubscription interface {
 	Chan() <-chan *models.TickerEvent
 	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
 }
 
 type tickerSubscription struct {
 	ch          <-chan *models.TickerEvent
-	onEvent     func(ticker *models.TickerEvent)
+	sub         *subscriber
 	unsubscribe func()
 }
 
@@ -56,14 +58,20 @@ func (s *tickerSubscription) Close() {
 	s.unsubscribe()
 }
 
+func (s *tickerSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
 type OrderBookSubscription interface {
 	Chan() <-chan *models.OrderBookEvent
 	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
 }
 
 type orderBookSubscription struct {
 	ch          <-chan *models.OrderBookEvent
-	onEvent     func(ob *models.OrderBookEvent)
+	sub         *subscriber
 	unsubscribe func()
 }
 
@@ -75,14 +83,20 @@ func (s *orderBookSubscription) Close() {
 	s.unsubscribe()
 }
 
+func (s *orderBookSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
 type TradeSubscription interface {
 	Chan() <-chan *models.TradeEvent
 	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
 }
 
 type tradeSubscription struct {
 	ch          <-chan *models.TradeEvent
-	onEvent     func(t *models.TradeEvent)
+	sub         *subscriber
 	unsubscribe func()
 }
 
@@ -94,21 +108,106 @@ func (s *tradeSubscription) Close() {
 	s.unsubscribe()
 }
 
+func (s *tradeSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
 type AccountSubscription interface {
-	Chan() <-chan models.AccountEvent
+	Chan() <-chan *models.AccountEvent
 	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
 }
 
 type accountSubscription struct {
-	ch          <-chan models.AccountEvent
-	onEvent     func(t models.AccountEvent)
+	ch          <-chan *models.AccountEvent
+	sub         *subscriber
 	unsubscribe func()
 }
 
-func (s *accountSubscription) Chan() <-chan models.AccountEvent {
+func (s *accountSubscription) Chan() <-chan *models.AccountEvent {
 	return s.ch
 }
 
 func (s *accountSubscription) Close() {
 	s.unsubscribe()
 }
+
+func (s *accountSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
+type OrderSubscription interface {
+	Chan() <-chan *models.OrderEvent
+	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
+}
+
+type orderSubscription struct {
+	ch          <-chan *models.OrderEvent
+	sub         *subscriber
+	unsubscribe func()
+}
+
+func (s *orderSubscription) Chan() <-chan *models.OrderEvent {
+	return s.ch
+}
+
+func (s *orderSubscription) Close() {
+	s.unsubscribe()
+}
+
+func (s *orderSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
+type MyTradeSubscription interface {
+	Chan() <-chan *models.Trade
+	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
+}
+
+type myTradeSubscription struct {
+	ch          <-chan *models.Trade
+	sub         *subscriber
+	unsubscribe func()
+}
+
+func (s *myTradeSubscription) Chan() <-chan *models.Trade {
+	return s.ch
+}
+
+func (s *myTradeSubscription) Close() {
+	s.unsubscribe()
+}
+
+func (s *myTradeSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
+type MarketSubscription interface {
+	Chan() <-chan *models.MarketEvent
+	Close()
+	// Dropped returns the number of events dropped by the overflow policy.
+	Dropped() uint64
+}
+
+type marketSubscription struct {
+	ch          <-chan *models.MarketEvent
+	sub         *subscriber
+	unsubscribe func()
+}
+
+func (s *marketSubscription) Chan() <-chan *models.MarketEvent {
+	return s.ch
+}
+
+func (s *marketSubscription) Close() {
+	s.unsubscribe()
+}
+
+func (s *marketSubscription) Dropped() uint64 {
+	return s.sub.Dropped()
+}
@@ -0,0 +1,333 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var bigTen = big.NewInt(10)
+
+// Decimal is an arbitrary-precision decimal number, the value is unscaled * 10^-scale.
+//
+// Decimal values are immutable, the zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the decimal number unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// NewDecimalFromInt returns the decimal number of an integer.
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns the shortest decimal number which rounds to f.
+//
+// It is meant for migrating float based code, prefer ParseDecimal for
+// values coming from user inputs or the MAX APIs. It panics if f is NaN
+// or infinite.
+func NewDecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		panic(fmt.Sprintf("types: cannot convert %v to decimal", f))
+	}
+	return d
+}
+
+func newDecimal(unscaled *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		unscaled = new(big.Int).Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+// maxExponent bounds the exponent accepted by ParseDecimal, so that an
+// input like "1e999999999" cannot allocate a huge number.
+const maxExponent = 1000
+
+// ParseDecimal parses a decimal number like "123", "-0.001" or "1.5e-8".
+// The exponent must be between -1000 and 1000.
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	exp := int64(0)
+
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+		}
+		if e < -maxExponent || e > maxExponent {
+			return Decimal{}, fmt.Errorf("types: exponent of decimal %q out of range", s)
+		}
+		exp = e
+		str = str[:i]
+	}
+
+	scale := int64(0)
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		scale = int64(len(str) - i - 1)
+		str = str[:i] + str[i+1:]
+	}
+
+	digits := strings.TrimLeft(str, "+-")
+	if digits == "" || len(str)-len(digits) > 1 || strings.IndexFunc(digits, notDigit) >= 0 {
+		return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+	}
+
+	unscaled, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+	}
+
+	return newDecimal(unscaled, int32(scale-exp)), nil
+}
+
+func notDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s cannot be parsed.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns the unscaled value of d with the given (larger) scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.value()
+	}
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+func maxScale(d1, d2 Decimal) int32 {
+	if d1.scale > d2.scale {
+		return d1.scale
+	}
+	return d2.scale
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 according to the sign of d.
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Add returns d + d2.
+func (d Decimal) Add(d2 Decimal) Decimal {
+	scale := maxScale(d, d2)
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - d2.
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	scale := maxScale(d, d2)
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * d2.
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.value(), d2.value()), scale: d.scale + d2.scale}
+}
+
+// Div returns d / d2 rounded half away from zero to the given number of
+// digits after the decimal point. It panics if d2 is 0.
+func (d Decimal) Div(d2 Decimal, scale int32) Decimal {
+	num := d.value()
+	den := d2.value()
+
+	if shift := scale - d.scale + d2.scale; shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+
+	return newDecimal(quoRound(num, den), scale)
+}
+
+// quoRound returns num / den rounded half away from zero.
+func quoRound(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
+
+// Round rounds d half away from zero to the given number of digits
+// after the decimal point.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+	return newDecimal(quoRound(d.value(), pow10(d.scale-places)), places)
+}
+
+// Truncate rounds d toward zero to the given number of digits after the decimal point.
+func (d Decimal) Truncate(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+	return newDecimal(new(big.Int).Quo(d.value(), pow10(d.scale-places)), places)
+}
+
+// Cmp compares d and d2 and returns -1, 0 or +1.
+func (d Decimal) Cmp(d2 Decimal) int {
+	scale := maxScale(d, d2)
+	return d.rescale(scale).Cmp(d2.rescale(scale))
+}
+
+// Equal reports whether d and d2 have the same value, regardless of their scales.
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+// LessThan reports whether d < d2.
+func (d Decimal) LessThan(d2 Decimal) bool {
+	return d.Cmp(d2) < 0
+}
+
+// GreaterThan reports whether d > d2.
+func (d Decimal) GreaterThan(d2 Decimal) bool {
+	return d.Cmp(d2) > 0
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d without exponent, keeping its scale, e.g. "0.10".
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.value()).String()
+
+	if d.scale > 0 {
+		if pad := int(d.scale) - len(s) + 1; pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// StringFixed formats d rounded half away from zero with exactly the given
+// number of digits after the decimal point, e.g. "0.100" for 3 places.
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+
+	d = d.Round(places)
+	if d.scale < places {
+		d = Decimal{unscaled: d.rescale(places), scale: places}
+	}
+
+	return d.String()
+}
+
+// MarshalJSON encodes d as a JSON string, the format used by the MAX APIs.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts JSON strings and numbers, null and "" are decoded as 0.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	quoted := len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"'
+	if quoted {
+		b = b[1 : len(b)-1]
+	}
+	if bytes.IndexByte(b, '"') >= 0 {
+		return fmt.Errorf("types: invalid decimal JSON %s", b)
+	}
+	if len(b) == 0 || string(b) == "null" {
+		*d = Decimal{}
+		return nil
+	}
+
+	return d.UnmarshalText(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(b []byte) error {
+	v, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"0", "0"},
+		{"123", "123"},
+		{"-0.001", "-0.001"},
+		{"0.10", "0.10"},
+		{"+5.", "5"},
+		{".5", "0.5"},
+		{"1.5e-8", "0.000000015"},
+		{"1.5E3", "1500"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) error: %v", test.in, err)
+			continue
+		}
+		if d.String() != test.out {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", test.in, d, test.out)
+		}
+	}
+
+	for _, in := range []string{"", "-", "1.2.3", "abc", "--1", "1e", "0x10", "1e999999999", "1e-1001"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) should fail", in)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+
+	if got := d("0.1").Add(d("0.2")); got.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+	if got := d("1").Sub(d("0.00000001")); got.String() != "0.99999999" {
+		t.Errorf("1 - 0.00000001 = %s", got)
+	}
+	if got := d("1.5").Mul(d("-0.02")); got.String() != "-0.030" {
+		t.Errorf("1.5 * -0.02 = %s", got)
+	}
+	if got := d("2").Div(d("3"), 4); got.String() != "0.6667" {
+		t.Errorf("2 / 3 = %s", got)
+	}
+	if got := d("-2").Div(d("3"), 4); got.String() != "-0.6667" {
+		t.Errorf("-2 / 3 = %s", got)
+	}
+	if got := d("1.2345").Round(2); got.String() != "1.23" {
+		t.Errorf("Round(1.2345, 2) = %s", got)
+	}
+	if got := d("-1.235").Round(2); got.String() != "-1.24" {
+		t.Errorf("Round(-1.235, 2) = %s", got)
+	}
+	if got := d("1.239").Truncate(2); got.String() != "1.23" {
+		t.Errorf("Truncate(1.239, 2) = %s", got)
+	}
+	if got := d("1.5").StringFixed(3); got != "1.500" {
+		t.Errorf("StringFixed(1.5, 3) = %s", got)
+	}
+	if got := d("1.255").StringFixed(2); got != "1.26" {
+		t.Errorf("StringFixed(1.255, 2) = %s", got)
+	}
+	if !d("1.50").Equal(d("1.5")) {
+		t.Error("1.50 != 1.5")
+	}
+	if !d("0.3").LessThan(d("0.30000000000000004")) {
+		t.Error("0.3 >= 0.30000000000000004")
+	}
+	if !(Decimal{}).IsZero() {
+		t.Error("zero value is not 0")
+	}
+	if got := NewDecimalFromFloat(0.1); got.String() != "0.1" {
+		t.Errorf("NewDecimalFromFloat(0.1) = %s", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	v := struct {
+		Price  Decimal `json:"price"`
+		Volume Decimal `json:"volume"`
+		Empty  Decimal `json:"empty"`
+	}{}
+
+	in := `{"price":"0.00000123","volume":12.50,"empty":null}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := json.Marshal(v)
+	if string(out) != `{"price":"0.00000123","volume":"12.50","empty":"0"}` {
+		t.Errorf("round trip = %s", out)
+	}
+
+	d := Decimal{}
+	for _, in := range []string{`"1`, `1"`, `"`, `""1""`} {
+		if err := d.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) should fail", in)
+		}
+	}
+}
@@ -14,35 +14,81 @@
 
 package types
 
-import (
-	"strconv"
-)
-
-type Price = float64
+// Price is an exact decimal price.
+//
+// Use NewDecimalFromFloat() and Decimal.Float64() to migrate float64 based code.
+type Price = Decimal
 
-type Volume = float64
+// Volume is an exact decimal volume.
+//
+// Use NewDecimalFromFloat() and Decimal.Float64() to migrate float64 based code.
+type Volume = Decimal
 
 type Timestamp = int32
 
 func ParsePrice(s string) (Price, error) {
-	return strconv.ParseFloat(s, 64)
+	return ParseDecimal(s)
 }
 
 func ParseVolume(s string) (Volume, error) {
-	return strconv.ParseFloat(s, 64)
+	return ParseDecimal(s)
 }
 
 // DepositState represents the deposit state
-type DepositState = string
+type DepositState string
 
 // OrderType represtns the order type, e.g., limit, market, etc.
-type OrderType = string
+type OrderType string
 
 // WithdrawalState represents the withdrawal state
-type WithdrawalState = string
+type WithdrawalState string
 
 // OrderSide indicates the order is a sell order or buy order
-type OrderSide = string
+type OrderSide string
+
+// OrderState represents the order state
+type OrderState string
+
+// Order states, see OrderState
+const (
+	OrderStateWait    OrderState = "wait"
+	OrderStateDone    OrderState = "done"
+	OrderStateConvert OrderState = "convert"
+	OrderStateCancel  OrderState = "cancel"
+)
+
+// Order sides, see OrderSide
+const (
+	OrderSideSell OrderSide = "sell"
+	OrderSideBuy  OrderSide = "buy"
+)
 
-// DepositState represents the order state
-type OrderState = string
+// Deposit states, see DepositState
+const (
+	DepositStateSubmitting      DepositState = "submitting"
+	DepositStateCancelled       DepositState = "cancelled"
+	DepositStateSubmitted       DepositState = "submitted"
+	DepositStateSuspended       DepositState = "suspended"
+	DepositStateRejected        DepositState = "rejected"
+	DepositStateAccepted        DepositState = "accepted"
+	DepositStateRefunded        DepositState = "refunded"
+	DepositStateSuspect         DepositState = "suspect"
+	DepositStateRefundCancelled DepositState = "refund_cancelled"
+)
+
+// Withdrawal states, see WithdrawalState
+const (
+	WithdrawalStateSubmitting WithdrawalState = "submitting"
+	WithdrawalStateSubmitted  WithdrawalState = "submitted"
+	WithdrawalStateRejected   WithdrawalState = "rejected"
+	WithdrawalStateAccepted   WithdrawalState = "accepted"
+	WithdrawalStateSuspect    WithdrawalState = "suspect"
+	WithdrawalStateApproved   WithdrawalState = "approved"
+	WithdrawalStateProcessing WithdrawalState = "processing"
+	WithdrawalStateRetryable  WithdrawalState = "retryable"
+	WithdrawalStateSent       WithdrawalState = "sent"
+	WithdrawalStateCancelled  WithdrawalState = "cancelled"
+	WithdrawalStateFailed     WithdrawalState = "failed"
+	WithdrawalStatePending    WithdrawalState = "pending"
+	WithdrawalStateConfirmed  WithdrawalState = "confirmed"
+)
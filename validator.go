@@ -0,0 +1,256 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+// PrecisionPolicy tells the order validator what to do with prices and
+// volumes having more digits than the market allows.
+type PrecisionPolicy int
+
+const (
+	// PrecisionReject fails the call with a *ValidationError.
+	PrecisionReject PrecisionPolicy = iota
+	// PrecisionRound rounds prices half away from zero and truncates volumes,
+	// so that an order never exceeds the requested volume.
+	PrecisionRound
+)
+
+// ErrValidation is matched by every *ValidationError with errors.Is.
+var ErrValidation = errors.New("max: invalid order")
+
+// ValidationError is returned by CreateOrder() and CreateOrders() when an
+// order is rejected by the validator, before any request is sent.
+type ValidationError struct {
+	// market id
+	Market string
+	// invalid field, e.g. price, volume or ord_type
+	Field string
+	// invalid value, as sent to the server
+	Value string
+	// human readable reason
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Value != "" {
+		return fmt.Sprintf("max: invalid %s %s %q: %s", e.Market, e.Field, e.Value, e.Reason)
+	}
+
+	return fmt.Sprintf("max: invalid %s %s: %s", e.Market, e.Field, e.Reason)
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// IsValidationError reports whether err is an order rejected by the validator.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+type orderValidator struct {
+	policy PrecisionPolicy
+	load   func(ctx context.Context) ([]*models.Market, error)
+
+	mu      sync.Mutex
+	markets map[string]*models.Market
+}
+
+func newOrderValidator(policy PrecisionPolicy, load func(ctx context.Context) ([]*models.Market, error)) *orderValidator {
+	return &orderValidator{
+		policy: policy,
+		load:   load,
+	}
+}
+
+// market returns the cached market metadata, loading them on first use
+// and again when the market is unknown, e.g. newly listed.
+func (v *orderValidator) market(ctx context.Context, id string) (*models.Market, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if m, ok := v.markets[id]; ok {
+		return m, nil
+	}
+
+	markets, err := v.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v.markets = make(map[string]*models.Market, len(markets))
+	for _, m := range markets {
+		v.markets[m.Id] = m
+	}
+
+	if m, ok := v.markets[id]; ok {
+		return m, nil
+	}
+
+	return nil, &ValidationError{Market: id, Field: "market", Reason: "unknown market"}
+}
+
+// validate checks an order and returns it with its prices and volume
+// adjusted to the market precision.
+func (v *orderValidator) validate(ctx context.Context, market string, o models.OrderRequest) (models.OrderRequest, error) {
+	m, err := v.market(ctx, market)
+	if err != nil {
+		return o, err
+	}
+
+	invalid := func(field string, value types.Decimal, reason string) error {
+		return &ValidationError{Market: market, Field: field, Value: value.String(), Reason: reason}
+	}
+
+	if o.Side != types.OrderSideBuy && o.Side != types.OrderSideSell {
+		return o, &ValidationError{Market: market, Field: "side", Value: string(o.Side), Reason: "must be buy or sell"}
+	}
+
+	ordType := o.OrderType
+	if ordType == "" {
+		ordType = OrderTypeLimit
+	}
+
+	var needPrice, needStopPrice bool
+	switch ordType {
+	case OrderTypeLimit:
+		needPrice = true
+	case OrderTypeMarket:
+	case OrderTypeStopLimit:
+		needPrice, needStopPrice = true, true
+	case OrderTypeStopMarket:
+		needStopPrice = true
+	default:
+		return o, &ValidationError{Market: market, Field: "ord_type", Value: string(ordType), Reason: "unknown order type"}
+	}
+
+	if needPrice != !o.Price.IsZero() {
+		if needPrice {
+			return o, invalid("price", o.Price, "required by "+string(ordType)+" orders")
+		}
+		return o, invalid("price", o.Price, "not allowed for "+string(ordType)+" orders")
+	}
+	if needStopPrice != !o.StopPrice.IsZero() {
+		if needStopPrice {
+			return o, invalid("stop_price", o.StopPrice, "required by "+string(ordType)+" orders")
+		}
+		return o, invalid("stop_price", o.StopPrice, "not allowed for "+string(ordType)+" orders")
+	}
+
+	if o.Price, err = v.fit(o.Price, m.QuoteUnitPrecision, false); err != nil {
+		return o, invalid("price", o.Price, err.Error())
+	}
+	if o.StopPrice, err = v.fit(o.StopPrice, m.QuoteUnitPrecision, false); err != nil {
+		return o, invalid("stop_price", o.StopPrice, err.Error())
+	}
+	if o.Volume, err = v.fit(o.Volume, m.BaseUnitPrecision, true); err != nil {
+		return o, invalid("volume", o.Volume, err.Error())
+	}
+
+	if o.Price.Sign() < 0 {
+		return o, invalid("price", o.Price, "must be positive")
+	}
+	if o.StopPrice.Sign() < 0 {
+		return o, invalid("stop_price", o.StopPrice, "must be positive")
+	}
+	if o.Volume.Sign() <= 0 {
+		return o, invalid("volume", o.Volume, "must be positive")
+	}
+
+	if min := types.NewDecimalFromFloat(m.MinBaseAmount); o.Volume.LessThan(min) {
+		return o, invalid("volume", o.Volume, fmt.Sprintf("below the minimum of %s %s", min, m.BaseUnit))
+	}
+
+	price := o.Price
+	if price.IsZero() {
+		price = o.StopPrice
+	}
+	if min := types.NewDecimalFromFloat(m.MinQuoteAmount); !price.IsZero() && price.Mul(o.Volume).LessThan(min) {
+		return o, invalid("volume", o.Volume, fmt.Sprintf("amount below the minimum of %s %s", min, m.QuoteUnit))
+	}
+
+	return o, nil
+}
+
+// fit adjusts d to the given number of digits after the decimal point
+// according to the precision policy.
+func (v *orderValidator) fit(d types.Decimal, precision int32, truncate bool) (types.Decimal, error) {
+	if d.Scale() <= precision {
+		return d, nil
+	}
+
+	adjusted := d.Truncate(precision)
+	if !truncate {
+		adjusted = d.Round(precision)
+	}
+
+	if adjusted.Equal(d) {
+		return adjusted, nil
+	}
+
+	if v.policy != PrecisionRound {
+		return d, fmt.Errorf("more than %d decimal places", precision)
+	}
+
+	return adjusted, nil
+}
+
+// validateOptions validates the order described by the options of
+// CreateOrder() and rewrites its prices.
+func (v *orderValidator) validateOptions(ctx context.Context, market string, side types.OrderSide, volume types.Volume, o Options) (types.Volume, error) {
+	req := models.OrderRequest{
+		Side:   side,
+		Volume: volume,
+	}
+
+	var err error
+	if s, ok := o["price"].(string); ok {
+		if req.Price, err = types.ParsePrice(s); err != nil {
+			return volume, &ValidationError{Market: market, Field: "price", Value: s, Reason: err.Error()}
+		}
+	}
+	if s, ok := o["stop_price"].(string); ok {
+		if req.StopPrice, err = types.ParsePrice(s); err != nil {
+			return volume, &ValidationError{Market: market, Field: "stop_price", Value: s, Reason: err.Error()}
+		}
+	}
+	if t, ok := o["ord_type"].(string); ok {
+		req.OrderType = types.OrderType(t)
+	}
+
+	req, err = v.validate(ctx, market, req)
+	if err != nil {
+		return volume, err
+	}
+
+	if _, ok := o["price"]; ok {
+		o["price"] = req.Price.String()
+	}
+	if _, ok := o["stop_price"]; ok {
+		o["stop_price"] = req.StopPrice.String()
+	}
+
+	return req.Volume, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+	"github.com/maicoin/max-exchange-api-go/types"
+)
+
+func newValidatorTestServer(orders *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/markets":
+			w.Write([]byte(`[{"id":"btctwd","base_unit":"btc","base_unit_precision":4,"quote_unit":"twd","quote_unit_precision":1,"min_base_amount":0.001,"min_quote_amount":250}]`))
+		case "/api/v2/orders", "/api/v2/orders/multi":
+			*orders++
+			w.Write([]byte(`{"id":1,"price":"100000.1","volume":"0.0123"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestValidateOrdersReject(t *testing.T) {
+	orders := 0
+	srv := newValidatorTestServer(&orders)
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"), ValidateOrders(PrecisionReject))
+	defer c.Close()
+
+	ctx := context.Background()
+	volume := types.MustParseDecimal("0.0123")
+
+	tests := []struct {
+		name  string
+		field string
+		side  types.OrderSide
+		opts  []CallOption
+	}{
+		{"price precision", "price", "buy", []CallOption{Price(types.MustParseDecimal("100000.12"))}},
+		{"missing price", "price", "buy", nil},
+		{"market with price", "price", "sell", []CallOption{OrderType(OrderTypeMarket), Price(types.MustParseDecimal("1"))}},
+		{"missing stop price", "stop_price", "sell", []CallOption{OrderType(OrderTypeStopLimit), Price(types.MustParseDecimal("1"))}},
+		{"side", "side", "bid", []CallOption{Price(types.MustParseDecimal("100000"))}},
+		{"min amount", "volume", "buy", []CallOption{Price(types.MustParseDecimal("1"))}},
+	}
+
+	for _, tt := range tests {
+		_, err := c.CreateOrder(ctx, "btctwd", tt.side, volume, tt.opts...)
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: got %v, want *ValidationError", tt.name, err)
+			continue
+		}
+		if verr.Field != tt.field {
+			t.Errorf("%s: Field = %s, want %s", tt.name, verr.Field, tt.field)
+		}
+		if !IsValidationError(err) {
+			t.Errorf("%s: IsValidationError = false", tt.name)
+		}
+	}
+
+	if _, err := c.CreateOrder(ctx, "ethtwd", "buy", volume, Price(types.MustParseDecimal("1"))); !IsValidationError(err) {
+		t.Errorf("unknown market: got %v, want a validation error", err)
+	}
+
+	if orders != 0 {
+		t.Errorf("%d orders were sent, want 0", orders)
+	}
+}
+
+func TestValidateOrdersRound(t *testing.T) {
+	orders := 0
+	srv := newValidatorTestServer(&orders)
+	defer srv.Close()
+
+	c := NewClient(BasePath(srv.URL), AuthToken("access", "secret"), ValidateOrders(PrecisionRound))
+	defer c.Close()
+
+	v := c.validator
+	req, err := v.validate(context.Background(), "btctwd", models.OrderRequest{
+		Side:   "buy",
+		Volume: types.MustParseDecimal("0.01239"),
+		Price:  types.MustParseDecimal("100000.15"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Price.String() != "100000.2" {
+		t.Errorf("Price = %s, want 100000.2", req.Price)
+	}
+	if req.Volume.String() != "0.0123" {
+		t.Errorf("Volume = %s, want 0.0123", req.Volume)
+	}
+
+	if _, err := c.CreateOrder(context.Background(), "btctwd", "buy", types.MustParseDecimal("0.01239"), Price(types.MustParseDecimal("100000.15"))); err != nil {
+		t.Fatal(err)
+	}
+	if orders != 1 {
+		t.Errorf("%d orders were sent, want 1", orders)
+	}
+}
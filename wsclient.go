@@ -15,25 +15,173 @@
 package max
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/maicoin/max-exchange-api-go/models"
 
-	event "github.com/asaskevich/EventBus"
 	"github.com/gorilla/websocket"
 )
 
+// Public channels of the websocket API, see SubscribeMarkets().
+const (
+	ChannelTicker    = "ticker"
+	ChannelOrderBook = "orderbook"
+	ChannelTrade     = "trade"
+)
+
+// ConnectionState is the state of the websocket connection,
+// see WSOnStateChange().
+type ConnectionState int
+
+// Connection states of the websocket client.
+const (
+	// StateConnecting means the client is dialing the server.
+	StateConnecting ConnectionState = iota
+	// StateConnected means the connection is established and the
+	// subscriptions are sent.
+	StateConnected
+	// StateAuthenticated means the server accepted the auth tokens.
+	StateAuthenticated
+	// StateReconnecting means the connection is lost and the client
+	// waits before dialing again.
+	StateReconnecting
+	// StateClosed means Close() was called.
+	StateClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateAuthenticated:
+		return "authenticated"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+
+	return "unknown"
+}
+
+// StalePolicy tells what to do when a subscription receives no data
+// for the stale timeout, see WSStaleTimeout().
+type StalePolicy int
+
+const (
+	// StaleReport reports a *StaleStreamError to the WSOnError() callback.
+	StaleReport StalePolicy = iota
+	// StaleReconnect reports the error and reconnects.
+	StaleReconnect
+)
+
+// StaleStreamError reports a subscription without data for too long.
+type StaleStreamError struct {
+	Channel string
+	Params  interface{}
+	// Idle is the time elapsed since the last data.
+	Idle time.Duration
+}
+
+func (e *StaleStreamError) Error() string {
+	return fmt.Sprintf("max: no %s data for %v, params %s", e.Channel, e.Idle.Round(time.Millisecond), toTopic(e.Channel, e.Params))
+}
+
+// DecodeError reports a stream message which could not be decoded.
+type DecodeError struct {
+	Channel string
+	// decoded JSON message
+	Msg map[string]interface{}
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Channel == "" {
+		return fmt.Sprintf("max: failed to decode message: %v", e.Err)
+	}
+	return fmt.Sprintf("max: failed to decode %s event: %v", e.Channel, e.Err)
+}
+
+// Unwrap returns the decoding error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+type activeSubscription struct {
+	req      *subscriptionSignature
+	lastSeen time.Time
+	stale    bool
+	// number of subscriptions sharing the topic
+	refs int
+}
+
+var (
+	// ErrWSClosed is returned when subscribing with a closed websocket client.
+	ErrWSClosed = errors.New("max: websocket client closed")
+	// ErrWSAuthRequired is returned when subscribing private events without WSAuthToken().
+	ErrWSAuthRequired = errors.New("max: websocket auth tokens required")
+)
+
+// wsWriteTimeout bounds the writes of the messages, so that a stuck
+// connection does not block the subscriptions.
+const wsWriteTimeout = 10 * time.Second
+
 // wsClient allow to connect and receive stream data
 // from max.com ws service.
 type wsClient struct {
-	conn   *websocket.Conn
-	connMu sync.RWMutex
-	stopCh chan struct{}
-	evBus  event.Bus
+	conn    *websocket.Conn
+	connMu  sync.RWMutex
+	writeMu sync.Mutex
+	dialer  *websocket.Dialer
+	stopCh  chan struct{}
+	bus     *dispatcher
+
+	stopOnce sync.Once
+	stopErr  error
+	stopMu   sync.RWMutex
+
+	// closing functions of the subscriptions, called by Close()
+	closers   map[*subscriber]func()
+	closersMu sync.Mutex
+
+	// active subscriptions by topic, sent again after reconnection
+	subs   map[string]*activeSubscription
+	subsMu sync.Mutex
+
+	// dropErr is the reason of a connection closed on purpose
+	dropErr error
+	dropMu  sync.Mutex
+
+	state         ConnectionState
+	stateMu       sync.RWMutex
+	onStateChange func(ConnectionState, error)
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	staleTimeout time.Duration
+	stalePolicy  StalePolicy
+	onError      func(error)
+
+	bufferSize     int
+	overflowPolicy OverflowPolicy
+
+	recorder *Recorder
+	// events decoded from the frame being handled, for the recorder
+	frameEvents []interface{}
 
 	accessKey string
 	secretKey string
@@ -42,188 +190,855 @@ type wsClient struct {
 }
 
 // NewWSClient returns a websocket client.
+//
+// The client reconnects with backoff when the connection is lost, then
+// authenticates and subscribes the active subscriptions again.
 func NewWSClient(opts ...WebsocketClientOption) (*wsClient, error) {
+	return NewWSClientContext(context.Background(), opts...)
+}
+
+// NewWSClientContext returns a websocket client closed when ctx is done,
+// see NewWSClient().
+func NewWSClientContext(ctx context.Context, opts ...WebsocketClientOption) (*wsClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	client := &wsClient{
-		stopCh: make(chan struct{}),
-		evBus:  event.New(),
-		URL:    "wss://max-ws.maicoin.com",
-		logger: log.New(os.Stdout, "", log.LstdFlags),
+		stopCh:       make(chan struct{}),
+		bus:          newDispatcher(),
+		closers:      make(map[*subscriber]func()),
+		subs:         make(map[string]*activeSubscription),
+		minBackoff:   1 * time.Second,
+		maxBackoff:   30 * time.Second,
+		pingInterval: 30 * time.Second,
+		pongTimeout:  10 * time.Second,
+		bufferSize:   defaultBufferSize,
+		URL:          "wss://max-ws.maicoin.com",
+		logger:       log.New(os.Stdout, "", log.LstdFlags),
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
 
-	d := &websocket.Dialer{
+	client.dialer = &websocket.Dialer{
 		Subprotocols:    []string{"p1", "p2"},
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		Proxy:           http.ProxyFromEnvironment,
 	}
 
-	var err error
-	client.conn, _, err = d.Dial(client.URL, nil)
+	conn, err := client.dial()
 	if err != nil {
 		return nil, err
 	}
 
-	go client.handleMsg()
+	go client.handleMsg(conn)
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				client.stop(ctx.Err())
+			case <-client.stopCh:
+			}
+		}()
+	}
+
+	if client.staleTimeout > 0 {
+		go client.watchStaleness()
+	}
 
 	return client, nil
 }
 
-// Close web socket connection
+// Close closes the websocket connection and the channels of all the
+// subscriptions. It can be called several times.
 func (w *wsClient) Close() {
-	w.stopCh <- struct{}{}
-	w.conn.Close()
+	w.stop(ErrWSClosed)
+}
+
+// Done returns a channel closed when the client is closed, by Close() or
+// by the context of NewWSClientContext().
+func (w *wsClient) Done() <-chan struct{} {
+	return w.stopCh
+}
+
+// Err returns nil until the client is closed, then ErrWSClosed if Close()
+// was called, or the error of the context of NewWSClientContext().
+func (w *wsClient) Err() error {
+	w.stopMu.RLock()
+	defer w.stopMu.RUnlock()
+
+	return w.stopErr
+}
+
+func (w *wsClient) stop(err error) {
+	w.stopOnce.Do(func() {
+		w.stopMu.Lock()
+		w.stopErr = err
+		w.stopMu.Unlock()
+
+		close(w.stopCh)
+
+		w.connMu.RLock()
+		w.conn.Close()
+		w.connMu.RUnlock()
+
+		if err == ErrWSClosed {
+			err = nil
+		}
+		w.setState(StateClosed, err)
+
+		w.closersMu.Lock()
+		closers := make([]func(), 0, len(w.closers))
+		for _, fn := range w.closers {
+			closers = append(closers, fn)
+		}
+		w.closersMu.Unlock()
+
+		for _, fn := range closers {
+			fn()
+		}
+		w.bus.Close()
+	})
+}
+
+// track registers the closing function of a subscription for Close(), and
+// returns it wrapped to be called only once.
+func (w *wsClient) track(sub *subscriber, closeFn func()) func() {
+	var once sync.Once
+	fn := func() {
+		once.Do(func() {
+			w.closersMu.Lock()
+			delete(w.closers, sub)
+			w.closersMu.Unlock()
+
+			closeFn()
+		})
+	}
+
+	w.closersMu.Lock()
+	w.closers[sub] = fn
+	w.closersMu.Unlock()
+
+	// Close() may have run since the subscription was checked.
+	if w.closed() {
+		fn()
+	}
+
+	return fn
+}
+
+// State returns the current connection state.
+func (w *wsClient) State() ConnectionState {
+	w.stateMu.RLock()
+	defer w.stateMu.RUnlock()
+
+	return w.state
+}
+
+func (w *wsClient) setState(state ConnectionState, err error) {
+	w.stateMu.Lock()
+	if w.state == StateClosed {
+		w.stateMu.Unlock()
+		return
+	}
+	w.state = state
+	w.stateMu.Unlock()
+
+	if w.onStateChange != nil {
+		w.onStateChange(state, err)
+	}
+}
+
+func (w *wsClient) closed() bool {
+	select {
+	case <-w.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// dial connects to the server and sends the active subscriptions.
+func (w *wsClient) dial() (*websocket.Conn, error) {
+	w.setState(StateConnecting, nil)
+
+	conn, _, err := w.dialer.Dial(w.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w.connMu.Lock()
+	w.conn = conn
+	w.connMu.Unlock()
+
+	if w.closed() {
+		conn.Close()
+		return nil, ErrWSClosed
+	}
+
+	w.setState(StateConnected, nil)
+
+	w.subsMu.Lock()
+	reqs := make([]*subscriptionSignature, 0, len(w.subs))
+	for _, sub := range w.subs {
+		sub.lastSeen = time.Now()
+		sub.stale = false
+		reqs = append(reqs, sub.req)
+	}
+	w.subsMu.Unlock()
+
+	for _, req := range reqs {
+		if err := w.sendMsg(req); err != nil {
+			w.logger.Println("Failed to subscribe", req.Channel, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// reconnect dials with backoff until it succeeds or the client is closed.
+func (w *wsClient) reconnect(cause error) *websocket.Conn {
+	policy := RetryPolicy{MinBackoff: w.minBackoff, MaxBackoff: w.maxBackoff}
+
+	w.setState(StateReconnecting, cause)
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-w.stopCh:
+			timer.Stop()
+			return nil
+		}
+
+		conn, err := w.dial()
+		if err == nil {
+			return conn
+		}
+		if w.closed() {
+			return nil
+		}
+
+		w.logger.Printf("Failed to reconnect, %v\n", err)
+		w.setState(StateReconnecting, err)
+	}
 }
 
 // SubscribeTicker subscribes the realtime price information
-func (w *wsClient) SubscribeTicker(market string, ch chan *models.TickerEvent) (TickerSubscription, error) {
-	handler := func(ev *models.TickerEvent) {
-		ch <- ev
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+func (w *wsClient) SubscribeTicker(market string, ch chan *models.TickerEvent, opts ...SubscribeOption) (TickerSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.TickerEvent):
+		case <-done:
+		}
 	}
 
-	unsubscriber, err := w.subscribeChannel("ticker", map[string]interface{}{
+	sub, unsubscriber, err := w.subscribeChannel("ticker", map[string]interface{}{
 		"market": market,
-	}, handler)
+	}, send, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &tickerSubscription{
-		ch:      ch,
-		onEvent: handler,
-		unsubscribe: func() {
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
 			unsubscriber()
 			close(ch)
-		},
+		}),
 	}, nil
 }
 
 // SubscribeOrderBook subscribes the realtime changes on order books
-func (w *wsClient) SubscribeOrderBook(market string, ch chan *models.OrderBookEvent) (OrderBookSubscription, error) {
-	handler := func(ev *models.OrderBookEvent) {
-		ch <- ev
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+func (w *wsClient) SubscribeOrderBook(market string, ch chan *models.OrderBookEvent, opts ...SubscribeOption) (OrderBookSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.OrderBookEvent):
+		case <-done:
+		}
 	}
 
-	unsubscriber, err := w.subscribeChannel("orderbook", map[string]interface{}{
+	sub, unsubscriber, err := w.subscribeChannel("orderbook", map[string]interface{}{
 		"market": market,
-	}, handler)
+	}, send, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &orderBookSubscription{
-		ch:      ch,
-		onEvent: handler,
-		unsubscribe: func() {
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
 			unsubscriber()
 			close(ch)
-		},
+		}),
 	}, nil
 }
 
 // SubscribeTrade subscribes the realtime trades information
-func (w *wsClient) SubscribeTrade(market string, ch chan *models.TradeEvent) (TradeSubscription, error) {
-	handler := func(ev *models.TradeEvent) {
-		ch <- ev
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+func (w *wsClient) SubscribeTrade(market string, ch chan *models.TradeEvent, opts ...SubscribeOption) (TradeSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.TradeEvent):
+		case <-done:
+		}
 	}
 
-	unsubscriber, err := w.subscribeChannel("trade", map[string]interface{}{
+	sub, unsubscriber, err := w.subscribeChannel("trade", map[string]interface{}{
 		"market": market,
-	}, handler)
+	}, send, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &tradeSubscription{
-		ch:      ch,
-		onEvent: handler,
-		unsubscribe: func() {
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
 			unsubscriber()
 			close(ch)
-		},
+		}),
 	}, nil
 }
 
 // SubscribeAccount subscribes the accounts changes for an user
 //
+// Available `SubscribeOption`:
+//     WithBuffer()
+//
 // Note:
 //     Use WSAuthToken() to pass your auth tokens.
-func (w *wsClient) SubscribeAccount(ch chan models.AccountEvent) (AccountSubscription, error) {
-	handler := func(ev models.AccountEvent) {
-		ch <- ev
+func (w *wsClient) SubscribeAccount(ch chan *models.AccountEvent, opts ...SubscribeOption) (AccountSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.AccountEvent):
+		case <-done:
+		}
 	}
 
-	topic := "account"
-	if err := w.evBus.SubscribeAsync(topic, handler, true); err != nil {
+	sub, unsubscriber, err := w.subscribePrivate("account", send, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	unsubscriber := func() {
-		w.evBus.Unsubscribe(topic, handler)
+	return &accountSubscription{
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
+			unsubscriber()
+			close(ch)
+		}),
+	}, nil
+}
+
+// SubscribeOrders subscribes the state changes of your orders, pushed by
+// the server once authenticated.
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+//
+// Note:
+//     Use WSAuthToken() to pass your auth tokens.
+func (w *wsClient) SubscribeOrders(ch chan *models.OrderEvent, opts ...SubscribeOption) (OrderSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.OrderEvent):
+		case <-done:
+		}
 	}
 
-	return &accountSubscription{
-		ch:      ch,
-		onEvent: handler,
-		unsubscribe: func() {
+	sub, unsubscriber, err := w.subscribePrivate("order", send, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orderSubscription{
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
 			unsubscriber()
 			close(ch)
-		},
+		}),
 	}, nil
 }
 
-func (w *wsClient) subscribeChannel(channel string, params interface{}, handler interface{}) (func(), error) {
-	req := &subscriptionSignature{
-		Cmd:     "subscribe",
-		Channel: channel,
-		Params:  params,
+// SubscribeMyTrades subscribes your trades with their fees, pushed by
+// the server once authenticated.
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+//
+// Note:
+//     Use WSAuthToken() to pass your auth tokens.
+func (w *wsClient) SubscribeMyTrades(ch chan *models.Trade, opts ...SubscribeOption) (MyTradeSubscription, error) {
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- ev.(*models.Trade):
+		case <-done:
+		}
 	}
 
-	topic := toTopic(channel, params)
-	if err := w.evBus.SubscribeAsync(topic, handler, true); err != nil {
+	sub, unsubscriber, err := w.subscribePrivate("my_trade", send, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	unsubscriber := func() {
-		w.evBus.Unsubscribe(topic, handler)
+	return &myTradeSubscription{
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
+			unsubscriber()
+			close(ch)
+		}),
+	}, nil
+}
+
+// SubscribeMarkets subscribes the channels, ChannelTicker, ChannelOrderBook
+// or ChannelTrade, of all the markets into a single stream of events
+// tagged with their channel and market.
+//
+// The events share the buffer of the subscription, OverflowCoalesce
+// coalesces the events of the same channel and market.
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+func (w *wsClient) SubscribeMarkets(channels, markets []string, ch chan *models.MarketEvent, opts ...SubscribeOption) (MarketSubscription, error) {
+	if w.closed() {
+		return nil, ErrWSClosed
+	}
+	for _, channel := range channels {
+		switch channel {
+		case ChannelTicker, ChannelOrderBook, ChannelTrade:
+		default:
+			return nil, fmt.Errorf("max: unknown channel %q", channel)
+		}
 	}
 
-	return unsubscriber, w.sendMsg(req)
+	send := func(ev interface{}, done <-chan struct{}) {
+		select {
+		case ch <- marketEvent(ev):
+		case <-done:
+		}
+	}
+
+	sub := w.newSubscriber(send, opts)
+	sub.key = func(ev interface{}) string {
+		e := marketEvent(ev)
+		return e.Channel + " " + e.Market
+	}
+
+	var removers []func()
+	seen := make(map[string]bool)
+	for _, channel := range channels {
+		for _, market := range markets {
+			if seen[channel+" "+market] {
+				continue
+			}
+			seen[channel+" "+market] = true
+
+			removers = append(removers, w.addTopic(channel, map[string]interface{}{
+				"market": market,
+			}, sub))
+		}
+	}
+
+	return &marketSubscription{
+		ch:  ch,
+		sub: sub,
+		unsubscribe: w.track(sub, func() {
+			for _, remove := range removers {
+				remove()
+			}
+			w.bus.Release(sub)
+			close(ch)
+		}),
+	}, nil
 }
 
-func (w *wsClient) sendMsg(msg interface{}) error {
-	w.connMu.Lock()
-	defer w.connMu.Unlock()
+// SubscribeAllTickers subscribes the tickers of all the markets returned
+// by Markets(), see SubscribeMarkets().
+//
+// Available `SubscribeOption`:
+//     WithBuffer()
+func (w *wsClient) SubscribeAllTickers(ctx context.Context, api PublicAPI, ch chan *models.MarketEvent, opts ...SubscribeOption) (MarketSubscription, error) {
+	markets, err := api.Markets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(markets))
+	for i, m := range markets {
+		ids[i] = m.Id
+	}
+
+	return w.SubscribeMarkets([]string{ChannelTicker}, ids, ch, opts...)
+}
 
-	return w.conn.WriteJSON(msg)
+// marketEvent tags an event of a public channel.
+func marketEvent(ev interface{}) *models.MarketEvent {
+	switch e := ev.(type) {
+	case *models.TickerEvent:
+		return &models.MarketEvent{Channel: ChannelTicker, Market: e.Market, Ticker: e}
+	case *models.OrderBookEvent:
+		return &models.MarketEvent{Channel: ChannelOrderBook, Market: e.Market, OrderBook: e}
+	case *models.TradeEvent:
+		return &models.MarketEvent{Channel: ChannelTrade, Market: e.Market, Trade: e}
+	}
+
+	return &models.MarketEvent{}
 }
 
-func (w *wsClient) readMsg(msg interface{}) error {
+// Dropped returns the number of events dropped by the overflow policies
+// of all the subscriptions, see WithBuffer().
+func (w *wsClient) Dropped() uint64 {
+	return w.bus.Dropped()
+}
+
+// newSubscriber applies the SubscribeOptions over the client defaults.
+func (w *wsClient) newSubscriber(send func(interface{}, <-chan struct{}), opts []SubscribeOption) *subscriber {
+	o := subscribeOptions{size: w.bufferSize, policy: w.overflowPolicy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return newSubscriber(o, send)
+}
+
+// subscribePrivate subscribes a topic of the events pushed to authenticated
+// connections, which needs no subscribe request.
+func (w *wsClient) subscribePrivate(topic string, send func(interface{}, <-chan struct{}), opts []SubscribeOption) (*subscriber, func(), error) {
+	if w.closed() {
+		return nil, nil, ErrWSClosed
+	}
+	if w.accessKey == "" || w.secretKey == "" {
+		return nil, nil, ErrWSAuthRequired
+	}
+
+	sub := w.newSubscriber(send, opts)
+	w.bus.Subscribe(topic, sub)
+
+	return sub, func() {
+		w.bus.Unsubscribe(topic, sub)
+		w.bus.Release(sub)
+	}, nil
+}
+
+func (w *wsClient) subscribeChannel(channel string, params interface{}, send func(interface{}, <-chan struct{}), opts []SubscribeOption) (*subscriber, func(), error) {
+	if w.closed() {
+		return nil, nil, ErrWSClosed
+	}
+
+	sub := w.newSubscriber(send, opts)
+	remove := w.addTopic(channel, params, sub)
+
+	return sub, func() {
+		remove()
+		w.bus.Release(sub)
+	}, nil
+}
+
+// addTopic subscribes a channel on the server, or shares the subscription
+// of the same channel and params if any, and returns the function removing
+// the subscriber. The server is asked to unsubscribe when the last
+// subscriber is removed.
+func (w *wsClient) addTopic(channel string, params interface{}, sub *subscriber) func() {
+	topic := toTopic(channel, params)
+	w.bus.Subscribe(topic, sub)
+
+	// writeMu is locked before releasing subsMu, so that the subscribe and
+	// unsubscribe requests of a topic are sent in order, while the write
+	// itself does not block the other users of subsMu.
+	var req *subscriptionSignature
+	w.subsMu.Lock()
+	if active, ok := w.subs[topic]; ok {
+		active.refs++
+	} else {
+		req = &subscriptionSignature{
+			Cmd:     "subscribe",
+			Channel: channel,
+			Params:  params,
+		}
+		w.subs[topic] = &activeSubscription{req: req, lastSeen: time.Now(), refs: 1}
+		w.writeMu.Lock()
+	}
+	w.subsMu.Unlock()
+
+	// A failed request is sent again once reconnected.
+	if req != nil {
+		if err := w.writeMsg(req); err != nil {
+			w.logger.Println("Failed to subscribe", channel, err)
+		}
+	}
+
+	return func() {
+		w.bus.Unsubscribe(topic, sub)
+
+		w.subsMu.Lock()
+		active, ok := w.subs[topic]
+		if !ok {
+			w.subsMu.Unlock()
+			return
+		}
+		if active.refs--; active.refs > 0 {
+			w.subsMu.Unlock()
+			return
+		}
+		delete(w.subs, topic)
+		if w.closed() {
+			w.subsMu.Unlock()
+			return
+		}
+		w.writeMu.Lock()
+		w.subsMu.Unlock()
+
+		req := &subscriptionSignature{
+			Cmd:     "unsubscribe",
+			Channel: channel,
+			Params:  params,
+		}
+		if err := w.writeMsg(req); err != nil {
+			w.logger.Println("Failed to unsubscribe", channel, err)
+		}
+	}
+}
+
+// SubscriptionInfo describes a channel subscribed on the server, see Subscriptions().
+type SubscriptionInfo struct {
+	Channel string
+	Params  interface{}
+	// Refs is the number of subscriptions sharing the channel.
+	Refs int
+	// LastSeen is the time of the last data received, or of the subscription.
+	LastSeen time.Time
+	// Stale reports a subscription without data for the stale timeout.
+	Stale bool
+}
+
+// Subscriptions returns the channels subscribed on the server, sorted by
+// channel and params. The private events, pushed once authenticated, are
+// not included.
+func (w *wsClient) Subscriptions() []SubscriptionInfo {
+	w.subsMu.Lock()
+	topics := make([]string, 0, len(w.subs))
+	for topic := range w.subs {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	infos := make([]SubscriptionInfo, 0, len(topics))
+	for _, topic := range topics {
+		active := w.subs[topic]
+		infos = append(infos, SubscriptionInfo{
+			Channel:  active.req.Channel,
+			Params:   active.req.Params,
+			Refs:     active.refs,
+			LastSeen: active.lastSeen,
+			Stale:    active.stale,
+		})
+	}
+	w.subsMu.Unlock()
+
+	return infos
+}
+
+func (w *wsClient) sendMsg(msg interface{}) error {
+	w.writeMu.Lock()
+	return w.writeMsg(msg)
+}
+
+// writeMsg writes a message with writeMu locked by the caller, and unlocks it.
+func (w *wsClient) writeMsg(msg interface{}) error {
+	defer w.writeMu.Unlock()
+
 	w.connMu.RLock()
-	defer w.connMu.RUnlock()
+	conn := w.conn
+	w.connMu.RUnlock()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(msg)
+}
+
+// handleMsg reads the messages until the client is closed, reconnecting
+// whenever the connection is lost.
+func (w *wsClient) handleMsg(conn *websocket.Conn) {
+	for {
+		err := w.readMsgs(conn)
+		if w.closed() {
+			return
+		}
+
+		w.dropMu.Lock()
+		if w.dropErr != nil {
+			err, w.dropErr = w.dropErr, nil
+		}
+		w.dropMu.Unlock()
+
+		w.logger.Printf("Connection lost, %v\n", err)
+		conn.Close()
+
+		if conn = w.reconnect(err); conn == nil {
+			return
+		}
+	}
+}
 
-	return w.conn.ReadJSON(msg)
+// drop closes the connection to trigger a reconnection.
+func (w *wsClient) drop(err error) {
+	w.dropMu.Lock()
+	w.dropErr = err
+	w.dropMu.Unlock()
+
+	w.connMu.RLock()
+	w.conn.Close()
+	w.connMu.RUnlock()
 }
 
-func (w *wsClient) handleMsg() {
-	errCh := make(chan error, 1)
+// readMsgs handles the messages of a connection until it fails.
+//
+// The read deadline is extended by every message and pong, so that a
+// connection without any of them for a ping interval plus the pong
+// timeout is considered dead.
+func (w *wsClient) readMsgs(conn *websocket.Conn) error {
+	if w.pingInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go w.ping(conn, done)
+
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(w.pingInterval + w.pongTimeout))
+		})
+	}
+
 	for {
+		if w.pingInterval > 0 {
+			conn.SetReadDeadline(time.Now().Add(w.pingInterval + w.pongTimeout))
+		}
+
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		at := time.Now()
+
 		resp := subscriptionResponse{}
+		if err := json.Unmarshal(b, &resp); err != nil {
+			w.reportError(&DecodeError{Err: err})
+		} else {
+			w.handleResponse(resp)
+		}
 
+		if w.recorder != nil {
+			if err := w.recorder.record(at, b, w.frameEvents); err != nil {
+				w.reportError(err)
+			}
+			w.frameEvents = nil
+		}
+	}
+}
+
+// publish dispatches an event decoded by handleResponse(), and keeps it for
+// the recorder.
+func (w *wsClient) publish(topic string, ev interface{}) {
+	if w.recorder != nil {
+		w.frameEvents = append(w.frameEvents, ev)
+	}
+	w.bus.Publish(topic, ev)
+}
+
+// ping sends a ping every interval until done is closed.
+func (w *wsClient) ping(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+
+	for {
 		select {
-		case errCh <- w.readMsg(&resp):
-			if err := <-errCh; err != nil {
-				w.logger.Printf("Failed to read JSON, %v\n", err)
-				continue
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.pongTimeout)); err != nil {
+				w.logger.Println("Failed to ping", err)
 			}
+		case <-done:
+			return
+		}
+	}
+}
 
-			w.handleResponse(resp)
+// reportError logs err and passes it to the WSOnError() callback.
+func (w *wsClient) reportError(err error) {
+	w.logger.Println(err)
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
+
+// touch records that the subscription of the topic received data.
+func (w *wsClient) touch(topic string) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	if sub, ok := w.subs[topic]; ok {
+		sub.lastSeen = time.Now()
+		sub.stale = false
+	}
+}
+
+// watchStaleness checks the subscriptions until the client is closed, and
+// handles the ones without data for longer than the stale timeout.
+func (w *wsClient) watchStaleness() {
+	ticker := time.NewTicker(w.staleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
 		case <-w.stopCh:
 			return
 		}
+
+		if state := w.State(); state != StateConnected && state != StateAuthenticated {
+			continue
+		}
+
+		var errs []error
+		now := time.Now()
+		w.subsMu.Lock()
+		for _, sub := range w.subs {
+			if idle := now.Sub(sub.lastSeen); !sub.stale && idle > w.staleTimeout {
+				sub.stale = true
+				errs = append(errs, &StaleStreamError{
+					Channel: sub.req.Channel,
+					Params:  sub.req.Params,
+					Idle:    idle,
+				})
+			}
+		}
+		w.subsMu.Unlock()
+
+		if len(errs) == 0 {
+			continue
+		}
+
+		for _, err := range errs {
+			w.reportError(err)
+		}
+
+		if w.stalePolicy == StaleReconnect {
+			w.drop(errs[0])
+		}
 	}
 }
 
@@ -257,18 +1072,69 @@ func (w *wsClient) handleResponse(resp subscriptionResponse) {
 		}
 	case "authenticated":
 		w.logger.Println("Authenticated")
+		w.setState(StateAuthenticated, nil)
 	case "account":
-		go w.evBus.Publish("account", resp)
+		ev := &accountEventJSON{}
+
+		if err := mapStruct(resp, &ev); err != nil {
+			w.reportError(&DecodeError{Channel: "account", Msg: resp, Err: err})
+			return
+		}
+
+		accounts, err := ev.Accounts()
+		if err != nil {
+			w.reportError(&DecodeError{Channel: "account", Msg: resp, Err: err})
+			return
+		}
+
+		for _, e := range accounts {
+			w.publish("account", e)
+		}
+	case "order":
+		ev := &orderEventJSON{}
+
+		if err := mapStruct(resp, &ev); err != nil {
+			w.reportError(&DecodeError{Channel: "order", Msg: resp, Err: err})
+			return
+		}
+
+		e, err := ev.Order()
+		if err != nil {
+			w.reportError(&DecodeError{Channel: "order", Msg: resp, Err: err})
+			return
+		}
+
+		w.publish("order", e)
+	case "my_trade":
+		ev := &myTradeEventJSON{}
+
+		if err := mapStruct(resp, &ev); err != nil {
+			w.reportError(&DecodeError{Channel: "my_trade", Msg: resp, Err: err})
+			return
+		}
+
+		e, err := ev.Trade()
+		if err != nil {
+			w.reportError(&DecodeError{Channel: "my_trade", Msg: resp, Err: err})
+			return
+		}
+
+		w.publish("my_trade", e)
 	case "subscribed":
 		topic := toTopic(resp["channel"], map[string]interface{}{
 			"market": resp["market"],
 		})
 		w.logger.Println(topic, "subscribed")
+	case "unsubscribed":
+		topic := toTopic(resp["channel"], map[string]interface{}{
+			"market": resp["market"],
+		})
+		w.logger.Println(topic, "unsubscribed")
 	case "ticker":
 		ev := &tickerEventJSON{}
 
 		if err := mapStruct(resp, &ev); err != nil {
-			w.logger.Println("Failed to decode ticker response", err)
+			w.reportError(&DecodeError{Channel: "ticker", Msg: resp, Err: err})
 			return
 		}
 
@@ -278,16 +1144,17 @@ func (w *wsClient) handleResponse(resp subscriptionResponse) {
 
 		e, err := ev.Ticker()
 		if err != nil {
-			w.logger.Println("Failed to parse ticker event", err)
+			w.reportError(&DecodeError{Channel: "ticker", Msg: resp, Err: err})
 			return
 		}
 
-		go w.evBus.Publish(topic, e)
+		w.touch(topic)
+		w.publish(topic, e)
 	case "orderbook":
 		ev := &models.OrderBookEvent{}
 
 		if err := mapStruct(resp, &ev); err != nil {
-			w.logger.Println("Failed to decode orderbook response", err)
+			w.reportError(&DecodeError{Channel: "orderbook", Msg: resp, Err: err})
 			return
 		}
 
@@ -295,12 +1162,13 @@ func (w *wsClient) handleResponse(resp subscriptionResponse) {
 			"market": ev.Market,
 		})
 
-		go w.evBus.Publish(topic, ev)
+		w.touch(topic)
+		w.publish(topic, ev)
 	case "trade":
 		ev := &tradeEventJSON{}
 
 		if err := mapStruct(resp, &ev); err != nil {
-			w.logger.Println("Failed to decode trade response", err)
+			w.reportError(&DecodeError{Channel: "trade", Msg: resp, Err: err})
 			return
 		}
 
@@ -310,11 +1178,12 @@ func (w *wsClient) handleResponse(resp subscriptionResponse) {
 
 		e, err := ev.Trade()
 		if err != nil {
-			w.logger.Println("Failed to parse trade event", err)
+			w.reportError(&DecodeError{Channel: "trade", Msg: resp, Err: err})
 			return
 		}
 
-		go w.evBus.Publish(topic, e)
+		w.touch(topic)
+		w.publish(topic, e)
 	default:
 		b, _ := json.Marshal(resp)
 		w.logger.Println("Unhandled message", b)
@@ -14,7 +14,10 @@
 
 package max
 
-import "log"
+import (
+	"log"
+	"time"
+)
 
 type WebsocketClientOption func(*wsClient)
 
@@ -39,3 +42,69 @@ func WSLogging(logger *log.Logger) WebsocketClientOption {
 		c.logger = logger
 	}
 }
+
+// WSOnStateChange sets a callback notified of the connection state changes,
+// with the error which caused the change if any.
+//
+// The callback is called from the reading goroutine and must not block.
+func WSOnStateChange(fn func(state ConnectionState, err error)) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.onStateChange = fn
+	}
+}
+
+// WSReconnectBackoff sets the exponential backoff between reconnection
+// attempts, default to 1s up to 30s.
+func WSReconnectBackoff(min, max time.Duration) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// WSPing sets the interval of the pings sent to the server, default to 30s,
+// and how long to wait for a pong. The connection is considered dead
+// without any message nor pong for interval + timeout. Use 0 to disable pings.
+func WSPing(interval, timeout time.Duration) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.pingInterval = interval
+		c.pongTimeout = timeout
+	}
+}
+
+// WSStaleTimeout reports, and reconnects according to the policy,
+// subscriptions receiving no data for the timeout. Disabled by default.
+func WSStaleTimeout(timeout time.Duration, policy StalePolicy) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.staleTimeout = timeout
+		c.stalePolicy = policy
+	}
+}
+
+// WSOnError sets a callback notified of the stream errors, e.g. *StaleStreamError
+// or *DecodeError.
+//
+// The callback must not block.
+func WSOnError(fn func(err error)) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.onError = fn
+	}
+}
+
+// WSBuffer sets the default number of events buffered for each subscription
+// channel, default to 256, and what to do when the buffer is full, default
+// to OverflowBlock. Use WithBuffer() to configure a subscription.
+func WSBuffer(size int, policy OverflowPolicy) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.bufferSize = size
+		c.overflowPolicy = policy
+	}
+}
+
+// WSRecorder records the received frames and their events with the
+// recorder. Closing the client does not close the recorder.
+func WSRecorder(r *Recorder) WebsocketClientOption {
+	return func(c *wsClient) {
+		c.recorder = r
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright 2018 MaiCoin Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package max
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maicoin/max-exchange-api-go/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsTestServer is a websocket server accepting any auth and recording subscriptions.
+type wsTestServer struct {
+	*httptest.Server
+	URL          string
+	conns        chan *websocket.Conn
+	subscribes   chan string
+	unsubscribes chan string
+	writeMu      sync.Mutex
+}
+
+func (s *wsTestServer) write(conn *websocket.Conn, v interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.WriteJSON(v)
+}
+
+func newWSTestServer(read bool) *wsTestServer {
+	s := &wsTestServer{
+		conns:        make(chan *websocket.Conn, 10),
+		subscribes:   make(chan string, 10),
+		unsubscribes: make(chan string, 10),
+	}
+
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.conns <- conn
+		s.write(conn, map[string]interface{}{"info": "challenge", "msg": "nonce"})
+
+		for read {
+			req := subscriptionSignature{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req.Cmd {
+			case "auth":
+				s.write(conn, map[string]interface{}{"info": "authenticated"})
+			case "subscribe":
+				s.subscribes <- req.Channel
+			case "unsubscribe":
+				s.unsubscribes <- req.Channel
+			}
+		}
+	}))
+	s.URL = "ws" + strings.TrimPrefix(s.Server.URL, "http")
+
+	return s
+}
+
+func expectStates(t *testing.T, states chan ConnectionState, want ...ConnectionState) {
+	for _, s := range want {
+		select {
+		case got := <-states:
+			if got != s {
+				t.Fatalf("state = %v, want %v", got, s)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for state %v", s)
+		}
+	}
+}
+
+func TestWSClientReconnect(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	states := make(chan ConnectionState, 20)
+	c, err := NewWSClient(
+		WSURL(srv.URL),
+		WSAuthToken("access", "secret"),
+		WSLogging(log.New(ioutil.Discard, "", 0)),
+		WSReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+		WSOnStateChange(func(state ConnectionState, err error) {
+			states <- state
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(want ...ConnectionState) {
+		expectStates(t, states, want...)
+	}
+	expectSubscribe := func() {
+		select {
+		case ch := <-srv.subscribes:
+			if ch != "ticker" {
+				t.Fatalf("subscribed %s, want ticker", ch)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for subscribe")
+		}
+	}
+
+	expect(StateConnecting, StateConnected, StateAuthenticated)
+
+	if _, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent)); err != nil {
+		t.Fatal(err)
+	}
+	expectSubscribe()
+
+	// Drop the connection, the client should come back and subscribe again.
+	(<-srv.conns).Close()
+	expect(StateReconnecting, StateConnecting, StateConnected, StateAuthenticated)
+	expectSubscribe()
+
+	c.Close()
+	expect(StateClosed)
+	if _, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent)); err != ErrWSClosed {
+		t.Errorf("SubscribeTicker() after Close() = %v, want ErrWSClosed", err)
+	}
+}
+
+func TestWSClientStaleStream(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	states := make(chan ConnectionState, 20)
+	errs := make(chan error, 10)
+	c, err := NewWSClient(
+		WSURL(srv.URL),
+		WSLogging(log.New(ioutil.Discard, "", 0)),
+		WSReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+		WSStaleTimeout(50*time.Millisecond, StaleReconnect),
+		WSOnError(func(err error) {
+			errs <- err
+		}),
+		WSOnStateChange(func(state ConnectionState, err error) {
+			states <- state
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	expectStates(t, states, StateConnecting, StateConnected)
+	if _, err := c.SubscribeTrade("btctwd", make(chan *models.TradeEvent)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		if e, ok := err.(*StaleStreamError); !ok || e.Channel != "trade" {
+			t.Errorf("got %v, want a stale trade stream", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the stale stream error")
+	}
+	expectStates(t, states, StateReconnecting, StateConnecting, StateConnected)
+}
+
+func TestWSClientPongTimeout(t *testing.T) {
+	// The server never reads, hence never answers pings.
+	srv := newWSTestServer(false)
+	defer srv.Close()
+
+	states := make(chan ConnectionState, 20)
+	c, err := NewWSClient(
+		WSURL(srv.URL),
+		WSLogging(log.New(ioutil.Discard, "", 0)),
+		WSReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+		WSPing(20*time.Millisecond, 20*time.Millisecond),
+		WSOnStateChange(func(state ConnectionState, err error) {
+			states <- state
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	expectStates(t, states, StateConnecting, StateConnected, StateReconnecting)
+}
+
+func TestWSClientAccountEvents(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	states := make(chan ConnectionState, 20)
+	errs := make(chan error, 10)
+	c, err := NewWSClient(
+		WSURL(srv.URL),
+		WSAuthToken("access", "secret"),
+		WSLogging(log.New(ioutil.Discard, "", 0)),
+		WSOnError(func(err error) {
+			errs <- err
+		}),
+		WSOnStateChange(func(state ConnectionState, err error) {
+			states <- state
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sub, err := c.SubscribeAccount(make(chan *models.AccountEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := <-srv.conns
+	expectStates(t, states, StateConnecting, StateConnected, StateAuthenticated)
+	srv.write(conn, map[string]interface{}{"info": "account", "currency": "btc", "balance": "1.5", "locked": "0.25", "at": 1538000000000})
+	srv.write(conn, map[string]interface{}{"info": "account", "balance": "oops"})
+
+	select {
+	case ev := <-sub.Chan():
+		if ev.Currency != "btc" || ev.Balance.String() != "1.5" || ev.Locked.String() != "0.25" || ev.At.Unix() != 1538000000 {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the account event")
+	}
+
+	select {
+	case err := <-errs:
+		if e, ok := err.(*DecodeError); !ok || e.Channel != "account" {
+			t.Errorf("got %v, want an account decode error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the decode error")
+	}
+}
+
+func TestWSClientOrderEvents(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	states := make(chan ConnectionState, 20)
+	c, err := NewWSClient(
+		WSURL(srv.URL),
+		WSAuthToken("access", "secret"),
+		WSLogging(log.New(ioutil.Discard, "", 0)),
+		WSOnStateChange(func(state ConnectionState, err error) {
+			states <- state
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	orders, err := c.SubscribeOrders(make(chan *models.OrderEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err := c.SubscribeMyTrades(make(chan *models.Trade, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := <-srv.conns
+	expectStates(t, states, StateConnecting, StateConnected, StateAuthenticated)
+
+	order := map[string]interface{}{"info": "order", "id": 1, "market": "btctwd", "side": "buy", "volume": "1.0", "state": "wait", "executed_volume": "0"}
+	srv.write(conn, order)
+	order["executed_volume"] = "0.4"
+	srv.write(conn, order)
+	srv.write(conn, map[string]interface{}{"info": "my_trade", "id": 7, "order_id": 1, "market": "btctwd", "price": "100", "volume": "0.4", "fee": "0.0006", "fee_currency": "btc"})
+	order["executed_volume"], order["state"] = "1.0", "done"
+	srv.write(conn, order)
+
+	for _, want := range []models.OrderUpdate{models.OrderUpdateNew, models.OrderUpdatePartialFill, models.OrderUpdateDone} {
+		select {
+		case ev := <-orders.Chan():
+			if ev.Update != want || ev.ID != 1 {
+				t.Errorf("got %s order %d, want %s order 1", ev.Update, ev.ID, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for the %s order event", want)
+		}
+	}
+
+	select {
+	case trade := <-trades.Chan():
+		fee, currency := trade.Fee()
+		if trade.OrderID != 1 || fee.String() != "0.0006" || currency != "btc" || trade.Funds.String() != "40.0" {
+			t.Errorf("unexpected trade %+v", trade)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the trade event")
+	}
+}
+
+func TestWSClientPrivateRequiresAuth(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	c, err := NewWSClient(WSURL(srv.URL), WSLogging(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.SubscribeOrders(make(chan *models.OrderEvent)); err != ErrWSAuthRequired {
+		t.Errorf("SubscribeOrders() = %v, want ErrWSAuthRequired", err)
+	}
+}
+
+func TestWSClientContext(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := NewWSClientContext(ctx, WSURL(srv.URL), WSLogging(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("Err() = %v before cancel", err)
+	}
+
+	cancel()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("client not closed by the context")
+	}
+	if err := c.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+	if _, ok := <-sub.Chan(); ok {
+		t.Error("subscription channel not closed")
+	}
+
+	// Closing again must not panic.
+	sub.Close()
+	c.Close()
+	if err := c.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v after Close(), want context.Canceled", err)
+	}
+}
+
+func TestWSClientSharedSubscriptions(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	c, err := NewWSClient(WSURL(srv.URL), WSLogging(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sub1, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub2, err := c.SubscribeTicker("btctwd", make(chan *models.TickerEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subs := c.Subscriptions(); len(subs) != 1 || subs[0].Channel != "ticker" || subs[0].Refs != 2 {
+		t.Fatalf("Subscriptions() = %+v", subs)
+	}
+
+	sub1.Close()
+	sub2.Close()
+	select {
+	case ch := <-srv.unsubscribes:
+		if ch != "ticker" {
+			t.Errorf("unsubscribed %s, want ticker", ch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for unsubscribe")
+	}
+
+	if n := len(srv.subscribes); n != 1 {
+		t.Errorf("%d subscribe requests, want 1", n)
+	}
+	if n := len(srv.unsubscribes); n != 0 {
+		t.Errorf("%d more unsubscribe requests, want none", n)
+	}
+	if subs := c.Subscriptions(); len(subs) != 0 {
+		t.Errorf("Subscriptions() = %+v after Close()", subs)
+	}
+}
+
+func TestWSClientSubscribeMarkets(t *testing.T) {
+	srv := newWSTestServer(true)
+	defer srv.Close()
+
+	c, err := NewWSClient(WSURL(srv.URL), WSLogging(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.SubscribeMarkets([]string{"candle"}, []string{"btctwd"}, make(chan *models.MarketEvent)); err == nil {
+		t.Error("SubscribeMarkets() accepted an unknown channel")
+	}
+
+	sub, err := c.SubscribeMarkets([]string{ChannelTicker, ChannelTrade}, []string{"btctwd", "ethtwd", "btctwd"}, make(chan *models.MarketEvent, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	if subs := c.Subscriptions(); len(subs) != 4 {
+		t.Fatalf("%d subscriptions, want 4", len(subs))
+	}
+
+	conn := <-srv.conns
+	ticker := map[string]interface{}{"info": "ticker", "at": 1, "buy": "1", "sell": "2", "open": "1", "last": "1", "high": "2", "low": "1", "vol": "10"}
+	ticker["market"] = "ethtwd"
+	srv.write(conn, ticker)
+	srv.write(conn, map[string]interface{}{"info": "trade", "market": "btctwd", "at": 1, "price": "100", "volume": "1"})
+	ticker["market"] = "btctwd"
+	srv.write(conn, ticker)
+
+	want := []string{"ticker ethtwd", "trade btctwd", "ticker btctwd"}
+	for _, w := range want {
+		select {
+		case ev := <-sub.Chan():
+			if got := ev.Channel + " " + ev.Market; got != w {
+				t.Errorf("got %s event, want %s", got, w)
+			}
+			if (ev.Ticker == nil) == (ev.Trade == nil) {
+				t.Errorf("%s event = %+v", w, ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s event", w)
+		}
+	}
+}